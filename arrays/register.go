@@ -0,0 +1,7 @@
+package arrays
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("arrays", "arrays", RunArrays)
+}
@@ -2,32 +2,34 @@ package concurrency
 
 import (
 	"fmt"
+	"golang-tutorial/lessons"
+	"io"
 	"sync"
 	"time"
 )
 
-func write(ch chan int) {
+func write(w io.Writer, ch chan int) {
 	for i := 0; i < 5; i++ {
 		ch <- i
-		fmt.Println("successfully wrote", i, "to ch")
+		fmt.Fprintln(w, "successfully wrote", i, "to ch")
 	}
 	close(ch)
 }
 
-func process(i int, wg *sync.WaitGroup) {
-	fmt.Println("started goroutine", i)
-	time.Sleep(2 * time.Second)
-	fmt.Printf("Goroutine %d ended\n", i)
+func process(w io.Writer, i int, wg *sync.WaitGroup) {
+	fmt.Fprintln(w, "started goroutine", i)
+	lessons.Sleep(2 * time.Second)
+	fmt.Fprintf(w, "Goroutine %d ended\n", i)
 	wg.Done()
 }
 
 func processSingle(ch chan string) {
-	time.Sleep(10500 * time.Millisecond)
+	lessons.Sleep(10500 * time.Millisecond)
 	ch <- "process successful"
 }
 
-func RunBufferedPools() {
-	fmt.Printf("\nBeginning of description of buffered channels...\n")
+func RunBufferedPools(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of description of buffered channels...\n")
 	/*
 	All the channels we discussed in the previous tutorial were basically unbuffered. As we discussed in the channels
 	tutorial in detail, sends and receives to an unbuffered channel are blocking.
@@ -42,28 +44,28 @@ func RunBufferedPools() {
 	ch := make(chan string, 2)
 	ch <- "naveen"
 	ch <- "paul"
-	fmt.Println(<- ch)
-	fmt.Println(<- ch)
+	fmt.Fprintln(w, <- ch)
+	fmt.Fprintln(w, <- ch)
 	/*
 	In the program above, we create a buffered channel with a capacity of 2. Since the channel has a capacity of 2, it
 	is possible to write 2 strings into the channel without being blocked. We write 2 strings to the channel and the
 	channel does not block.
 	*/
 
-	fmt.Printf("\nBeginning of another example on buffered channels...\n")
+	fmt.Fprintf(w, "\nBeginning of another example on buffered channels...\n")
 	/*
 	Lets look at one more example of buffered channel in which the values to the channel are written in a concurrent
 	Goroutine and read from the main Goroutine.
 	 */
 	ch2 := make(chan int, 2)
-	go write(ch2)
-	time.Sleep(2 * time.Second)
+	go write(w, ch2)
+	lessons.Sleep(2 * time.Second)
 	for v := range ch2 {
-		fmt.Println("read value", v,"from ch")
-		time.Sleep(2 * time.Second)
+		fmt.Fprintln(w, "read value", v,"from ch")
+		lessons.Sleep(2 * time.Second)
 	}
 
-	fmt.Printf("\nBeginning of deadlocks...\n")
+	fmt.Fprintf(w, "\nBeginning of deadlocks...\n")
 	ch3 := make(chan string, 2)
 	ch3 <- "naveen"
 	ch3 <- "paul"
@@ -74,22 +76,22 @@ func RunBufferedPools() {
 	Goroutine must read from the channel in order for the write to proceed, but in this case there is no concurrent
 	routine reading from this channel. Hence there will be a deadlock and the program will panic at run time.
 	 */
-	fmt.Println(<- ch3)
-	fmt.Println(<- ch3)
+	fmt.Fprintln(w, <- ch3)
+	fmt.Fprintln(w, <- ch3)
 
-	fmt.Printf("\nBeginning of length vs capacity...\n")
+	fmt.Fprintf(w, "\nBeginning of length vs capacity...\n")
 	ch4 := make(chan string, 3)
 	ch4 <- "naveen"
 	ch4 <- "paul"
 	// below will print 3
-	fmt.Println("capacity is", cap(ch4))
+	fmt.Fprintln(w, "capacity is", cap(ch4))
 	// below will print 2
-	fmt.Println("length is", len(ch4))
-	fmt.Println("read value", <- ch4)
+	fmt.Fprintln(w, "length is", len(ch4))
+	fmt.Fprintln(w, "read value", <- ch4)
 	// below will print 1
-	fmt.Println("new length is", len(ch4))
+	fmt.Fprintln(w, "new length is", len(ch4))
 
-	fmt.Printf("\nBeginning of waitgroup...\n")
+	fmt.Fprintf(w, "\nBeginning of waitgroup...\n")
 	/*
 	A WaitGroup is used to wait for a collection of Goroutines to finish executing. The control is blocked until all
 	Goroutines finish executing. Lets say we have 3 concurrently executing Goroutines spawned from the main Goroutine.
@@ -115,12 +117,12 @@ func RunBufferedPools() {
 		It is important to pass the address of wg. If the address is not passed, then each Goroutine will have its
 		own copy of the WaitGroup and main will not be notified when they finish executing.
 		 */
-		go process(i, &wg)
+		go process(w, i, &wg)
 	}
 	wg.Wait()
-	fmt.Println("All goroutines finished executing")
+	fmt.Fprintln(w, "All goroutines finished executing")
 
-	fmt.Printf("\nBeginning of worker pool implementation...\n")
+	fmt.Fprintf(w, "\nBeginning of worker pool implementation...\n")
 	/*
 	One of the important uses of buffered channel is the implementation of worker pool.
 	In general, a worker pool is a collection of threads which are waiting for tasks to be assigned to them. Once they
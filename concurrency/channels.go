@@ -2,6 +2,8 @@ package concurrency
 
 import (
 	"fmt"
+	"golang-tutorial/lessons"
+	"io"
 	"time"
 )
 
@@ -9,15 +11,15 @@ func sendData(sendch chan<- int) {
 	sendch <- 10
 }
 
-func helloWorldWithoutSleep(done chan bool) {
-	fmt.Println("helloWorldWithoutSleep goroutine")
+func helloWorldWithoutSleep(w io.Writer, done chan bool) {
+	fmt.Fprintln(w, "helloWorldWithoutSleep goroutine")
 	done <- true
 }
 
-func helloWorldWithSleep(done2 chan bool) {
-	fmt.Println("helloWorldWithSleep goroutine is going to sleep")
-	time.Sleep(4 * time.Second)
-	fmt.Println("helloWorldWithSleep goroutine awake and going to write to done2 channel")
+func helloWorldWithSleep(w io.Writer, done2 chan bool) {
+	fmt.Fprintln(w, "helloWorldWithSleep goroutine is going to sleep")
+	lessons.Sleep(4 * time.Second)
+	fmt.Fprintln(w, "helloWorldWithSleep goroutine awake and going to write to done2 channel")
 	done2 <- true
 }
 
@@ -48,14 +50,14 @@ func producer(chnl chan int) {
 	close(chnl)
 }
 
-func RunChannels() {
-	fmt.Printf("\nBeginning of introduction to channels...\n")
+func RunChannels(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction to channels...\n")
 	/*
 	Channels can be thought as pipes using which Goroutines communicate. Similar to how water flows from one end to
 	another in a pipe, data can be sent from one end and received from the another end using channels.
 	*/
 
-	fmt.Printf("\nBeginning of declaring channels...\n")
+	fmt.Fprintf(w, "\nBeginning of declaring channels...\n")
 	/*
 	Each channel has a type associated with it. This type is the type of data that the channel is allowed to transport.
 	No other type is allowed to be transported using the channel.
@@ -65,21 +67,21 @@ func RunChannels() {
 	 */
 	var a chan int // this is a nil channel
 	if a == nil {
-		fmt.Println("Channel a is nil, going to define it")
+		fmt.Fprintln(w, "Channel a is nil, going to define it")
 		a = make(chan int)
-		fmt.Printf("Type of a is %T\n", a)
+		fmt.Fprintf(w, "Type of a is %T\n", a)
 	}
 	/*
 	As usual the short hand declaration is also a valid and concise way to define a channel.
 	 */
 	b := make(chan int)
 	if b == nil {
-		fmt.Println("Channel b is nil, going to define it")
+		fmt.Fprintln(w, "Channel b is nil, going to define it")
 	} else {
-		fmt.Println("Channel b is not nil")
+		fmt.Fprintln(w, "Channel b is not nil")
 	}
 
-	fmt.Printf("\nBeginning of sending and receiving from channel...\n")
+	fmt.Fprintf(w, "\nBeginning of sending and receiving from channel...\n")
 	/*
 	The syntax to send and receive data from a channel are given below:
 		data := <- a // read from channel a
@@ -92,9 +94,9 @@ func RunChannels() {
 	in other programming languages.
 	*/
 
-	fmt.Printf("\nBeginning of example program...\n")
+	fmt.Fprintf(w, "\nBeginning of example program...\n")
 	done := make(chan bool)
-	go helloWorldWithoutSleep(done)
+	go helloWorldWithoutSleep(w, done)
 	<- done
 	/*
 	Above line of code is blocking which means that until some Goroutine writes data to the done channel, the control
@@ -106,9 +108,9 @@ func RunChannels() {
 	as parameter, prints Hello world goroutine and then writes to the done channel. When this write is complete, the
 	main Goroutine receives the data from the done channel, it is unblocked and then the text main goroutine is printed.
 	 */
-	fmt.Println("main goroutine")
+	fmt.Fprintln(w, "main goroutine")
 
-	fmt.Printf("\nBeginning of another example program...\n")
+	fmt.Fprintf(w, "\nBeginning of another example program...\n")
 	/*
 	We will structure the program such that the squares are calculated in a separate Goroutine, cubes in another
 	Goroutine and the final summation happens in the main Goroutine.
@@ -119,9 +121,9 @@ func RunChannels() {
 	go calcSquares(number, sqrch)
 	go calcCubes(number, cubech)
 	squares, cubes := <- sqrch, <- cubech
-	fmt.Println("Final output", squares + cubes)
+	fmt.Fprintln(w, "Final output", squares + cubes)
 
-	fmt.Printf("\nBeginning of a deadlocks...\n")
+	fmt.Fprintf(w, "\nBeginning of a deadlocks...\n")
 	/*
 	One important factor to consider while using channels is deadlock. If a Goroutine is sending data on a channel, then
 	it is expected that some other Goroutine should be receiving the data. If this does not happen, then the program will
@@ -137,7 +139,7 @@ func RunChannels() {
 	Goroutine is receiving data from the channel ch. Hence this program will panic with the runtime error.
 	 */
 
-	fmt.Printf("\nBeginning of unidirectional channels...\n")
+	fmt.Fprintf(w, "\nBeginning of unidirectional channels...\n")
 	/*
 	All the channels we discussed so far are bidirectional channels, that is data can be both sent and received on them.
 	It is also possible to create unidirectional channels, that is channels that only send or receive data.
@@ -149,7 +151,7 @@ func RunChannels() {
 	pointing to chan. We try to receive data from a send only channel in below line. This is not allowed and when the
 	program is run, the compiler will complain stating,
 	 */
-	// fmt.Println(<-chnl)
+	// fmt.Fprintln(w, <-chnl)
 	/*
 	All is well but what is the point of writing to a send only channel if it cannot be read from!
 	This is where channel conversion comes into use. It is possible to convert a bidirectional channel to a send only or
@@ -157,7 +159,7 @@ func RunChannels() {
 	 */
 	chnl2 := make(chan int)
 	go sendData(chnl2)
-	fmt.Println(<- chnl2)
+	fmt.Fprintln(w, <- chnl2)
 	/*
 	In the above program, a bidirectional channel chnl is created. It is passed as a parameter to the sendData Goroutine.
 	The sendData function converts this channel to a send only channel in line no. 5 in the parameter
@@ -165,7 +167,7 @@ func RunChannels() {
 	Goroutine. This program will print 10 as the output.
 	 */
 
-	fmt.Printf("\nBeginning of closing channels and for range loops on channels...\n")
+	fmt.Fprintf(w, "\nBeginning of closing channels and for range loops on channels...\n")
 	/*
 	Senders have the ability to close the channel to notify receivers that no more data will be sent on the channel.
 	Receivers can use an additional variable while receiving data from the channel to check whether the channel has
@@ -181,10 +183,10 @@ func RunChannels() {
 	for {
 		v, ok := <- ch
 		if ok == false {
-			fmt.Println("Received", v, ok)
+			fmt.Fprintln(w, "Received", v, ok)
 			break
 		}
-		fmt.Println("Received", v, ok)
+		fmt.Fprintln(w, "Received", v, ok)
 	}
 	/*
 	The for range form of the for loop can be used to receive values from a channel until it is closed.
@@ -193,7 +195,7 @@ func RunChannels() {
 	ch2 := make(chan int)
 	go producer(ch2)
 	for v := range ch2 {
-		fmt.Println("Received", v)
+		fmt.Fprintln(w, "Received", v)
 	}
 	/*
 	In the above program, for range loop receives data from the ch2 channel until it is closed. Once ch2 is closed, the
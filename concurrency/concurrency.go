@@ -1,9 +1,12 @@
 package concurrency
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-func RunConcurrency() {
-	fmt.Printf("\nBeginning of introduction...\n")
+func RunConcurrency(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction...\n")
 	/*
 	Go is a concurrent language and not a parallel one.
 	Concurrency is the capability to deal with lots of things at once.
@@ -13,7 +16,7 @@ func RunConcurrency() {
 	things at once :)
 	*/
 
-	fmt.Printf("\nBeginning of parallelism vs concurrency...\n")
+	fmt.Fprintf(w, "\nBeginning of parallelism vs concurrency...\n")
 	/*
 	Parallelism is doing lots of things at the same time.
 	Lets understand it better with the same jogging example. In this case lets assume that the person is jogging and
@@ -41,7 +44,7 @@ func RunConcurrency() {
 	this communication overhead is high. Hence parallel programs do not always result in faster execution times!
 	 */
 
-	fmt.Printf("\nBeginning of support for concurrency in Go...\n")
+	fmt.Fprintf(w, "\nBeginning of support for concurrency in Go...\n")
 	/*
 	Concurrency is an natural part of the Go programming language. Concurrency is handled in Go using Goroutines
 	and channels.
@@ -2,6 +2,7 @@ package concurrency
 
 import (
 	"fmt"
+	"io"
 	"sync"
 )
 
@@ -40,8 +41,8 @@ func incrementWithChannel(wg *sync.WaitGroup, ch chan bool) {
 	wg.Done()
 }
 
-func RunMutexes() {
-	fmt.Printf("\nBeginning of critical selection...\n")
+func RunMutexes(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of critical selection...\n")
 	/*
 	Race condition is the term that output of the program depends on the sequence of execution of Goroutines.
 	When a program runs concurrently, the parts of code which modify shared resources should not be accessed by
@@ -50,7 +51,7 @@ func RunMutexes() {
 	the code at any point of time. This is made possible by using Mutex.
 	*/
 
-	fmt.Printf("\nBeginning of mutex...\n")
+	fmt.Fprintf(w, "\nBeginning of mutex...\n")
 	/*
 	First of all, Mutex is a struct type. A Mutex is used to provide a locking mechanism to ensure that only one
 	Goroutine is running the critical section of code at any point of time to prevent race condition from happening.
@@ -64,16 +65,16 @@ func RunMutexes() {
 	//x = x + 1
 	//mutex.Unlock()
 
-	fmt.Printf("\nBeginning of a simple program with race condition...\n")
-	var w sync.WaitGroup
+	fmt.Fprintf(w, "\nBeginning of a simple program with race condition...\n")
+	var wg sync.WaitGroup
 	for i := 0; i < 1000; i++ {
-		w.Add(1)
-		go increment(&w)
+		wg.Add(1)
+		go increment(&wg)
 	}
-	w.Wait()
-	fmt.Println("final value of x", x)
+	wg.Wait()
+	fmt.Fprintln(w, "final value of x", x)
 
-	fmt.Printf("\nBeginning of solution of above problem with mutex...\n")
+	fmt.Fprintf(w, "\nBeginning of solution of above problem with mutex...\n")
 	/*
 	In the program above, we spawn 1000 Goroutines. If each increments the value of x by 1, the final desired value of
 	x should be 1000. In this section, we will fix the race condition in the program above using mutex.
@@ -86,7 +87,7 @@ func RunMutexes() {
 		go incrementWithMutex(&w1, &m1)
 	}
 	w1.Wait()
-	fmt.Println("final value of x after solved with mutex", x)
+	fmt.Fprintln(w, "final value of x after solved with mutex", x)
 	/*
 	Mutex is a struct type and we create a zero valued variable m of type Mutex. In the above program we have changed
 	the increment function so that the code which increments x x = x + 1 is between m.Lock() and m.Unlock(). Now this
@@ -98,7 +99,7 @@ func RunMutexes() {
 	each Goroutine will have its own copy of the mutex and the race condition will still occur.
 	 */
 
-	fmt.Printf("\nBeginning of solution of above problem with channel...\n")
+	fmt.Fprintf(w, "\nBeginning of solution of above problem with channel...\n")
 	x = 0
 	var w2 sync.WaitGroup
 	ch := make(chan bool, 1)
@@ -107,7 +108,7 @@ func RunMutexes() {
 		go incrementWithChannel(&w2, ch)
 	}
 	w2.Wait()
-	fmt.Println("final value of x after solved with channel", x)
+	fmt.Fprintln(w, "final value of x after solved with channel", x)
 	/*
 	In the program above, we have created a buffered channel of capacity 1 and this is passed to the increment Goroutine.
 	This buffered channel is used to ensure that only one Goroutine access the critical section of code which increments
@@ -116,7 +117,7 @@ func RunMutexes() {
 	channel after incrementing x in line no. 10. Effectively this allows only one Goroutine to access the critical section.
 	 */
 
-	fmt.Printf("\nBeginning of mutex vs channel comparison...\n")
+	fmt.Fprintf(w, "\nBeginning of mutex vs channel comparison...\n")
 	/*
 	In general use channels when Goroutines need to communicate with each other and mutexes when only one Goroutine
 	should access the critical section of code. In the case of the problem which we solved above, I would prefer to use
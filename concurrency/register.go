@@ -0,0 +1,12 @@
+package concurrency
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("concurrency", "concurrency", RunConcurrency)
+	lessons.Register("concurrency", "channels", RunChannels)
+	lessons.Register("concurrency", "buffered_pools", RunBufferedPools)
+	lessons.Register("concurrency", "worker_pools_demo", RunWorkerPoolsDemo)
+	lessons.Register("concurrency", "select", RunSelect)
+	lessons.Register("concurrency", "mutexes", RunMutexes)
+}
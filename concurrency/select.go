@@ -2,16 +2,18 @@ package concurrency
 
 import (
 	"fmt"
+	"golang-tutorial/lessons"
+	"io"
 	"time"
 )
 
 func server1(ch chan string) {
-	time.Sleep(6 * time.Second)
+	lessons.Sleep(6 * time.Second)
 	ch <- "from server1"
 }
 
 func server2(ch chan string) {
-	time.Sleep(3 * time.Second)
+	lessons.Sleep(3 * time.Second)
 	ch <- "from server2"
 }
 
@@ -24,12 +26,12 @@ func server4(ch chan string) {
 }
 
 func processForSelect(ch chan string) {
-	time.Sleep(10500 * time.Millisecond)
+	lessons.Sleep(10500 * time.Millisecond)
 	ch <- "process successfull"
 }
 
-func RunSelect() {
-	fmt.Printf("\nBeginning of introduction...\n")
+func RunSelect(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction...\n")
 	/*
 	The select statement is used to choose from multiple send/receive channel operations. The select statement blocks
 	until one of the send/receive operation is ready. If multiple operations are ready, one of them is chosen at random.
@@ -41,9 +43,9 @@ func RunSelect() {
 	go server2(output2)
 	select {
 	case s1 := <- output1:
-		fmt.Println(s1)
+		fmt.Fprintln(w, s1)
 	case s2 := <- output2:
-		fmt.Println(s2)
+		fmt.Fprintln(w, s2)
 	}
 	/*
 	In the program above, the server1 function sleeps for 6 seconds then writes the text from server1 to
@@ -55,7 +57,7 @@ func RunSelect() {
 	Goroutine to write to the output2 channel
 	 */
 
-	fmt.Printf("\nBeginning of practical use of select...\n")
+	fmt.Fprintf(w, "\nBeginning of practical use of select...\n")
 	/*
 	The reason behind naming the functions in the above program as server1 and server2 is to illustrate the practical
 	use of select.
@@ -68,7 +70,7 @@ func RunSelect() {
 	and return the quickest response to the user :).
 	*/
 
-	fmt.Printf("\nBeginning of default case...\n")
+	fmt.Fprintf(w, "\nBeginning of default case...\n")
 	/*
 	The default case in a select statement is executed when none of the other case is ready. This is generally used to
 	prevent the select statement from blocking.
@@ -77,13 +79,13 @@ func RunSelect() {
 	go processSingle(ch0)
 	outer:
 		for {
-			time.Sleep(1000 * time.Millisecond)
+			lessons.Sleep(1000 * time.Millisecond)
 			select {
 			case v := <- ch0:
-				fmt.Println("received value: ", v)
+				fmt.Fprintln(w, "received value: ", v)
 				break outer
 			default:
-				fmt.Println("no value received")
+				fmt.Fprintln(w, "no value received")
 			}
 		}
 	/*
@@ -97,7 +99,7 @@ func RunSelect() {
 	terminate.
 	 */
 
-	fmt.Printf("\nBeginning of deadlock and default case...\n")
+	fmt.Fprintf(w, "\nBeginning of deadlock and default case...\n")
 	/*
 	In the program above, we have created a channel ch in line no. 4. We try to read from this channel inside the select
 	in line no. 6. The select statement will block forever since no other Goroutine is writing to this channel and hence
@@ -115,7 +117,7 @@ func RunSelect() {
 	select {
 	case <- ch:
 	default:
-		fmt.Println("default case executed to prevent deadlock")
+		fmt.Fprintln(w, "default case executed to prevent deadlock")
 	}
 	/*
 	Similarly the default case will be executed even if the select has only nil channels.
@@ -123,9 +125,9 @@ func RunSelect() {
 	var nilChannel chan string
 	select {
 	case v := <- nilChannel:
-		fmt.Println("received value", v)
+		fmt.Fprintln(w, "received value", v)
 	default:
-		fmt.Println("default case executed to prevent deadlock")
+		fmt.Fprintln(w, "default case executed to prevent deadlock")
 	}
 	/*
 	In the program above nilChannel is nil and we are trying to read from nilChannel in the select. If the default case
@@ -133,7 +135,7 @@ func RunSelect() {
 	the select, it will be executed
 	 */
 
-	fmt.Printf("\nBeginning of random selection...\n")
+	fmt.Fprintf(w, "\nBeginning of random selection...\n")
 	/*
 	When multiple cases in a select statement are ready, one of them will be executed at random.
 	 */
@@ -141,15 +143,15 @@ func RunSelect() {
 	output4 := make(chan string)
 	go server3(output3)
 	go server4(output4)
-	time.Sleep(1 * time.Second)
+	lessons.Sleep(1 * time.Second)
 	select {
 	case s3 := <- output3:
-		fmt.Println(s3)
+		fmt.Fprintln(w, s3)
 	case s4 := <- output4:
-		fmt.Println(s4)
+		fmt.Fprintln(w, s4)
 	}
 
-	fmt.Printf("\nBeginning of empty select...\n")
+	fmt.Fprintf(w, "\nBeginning of empty select...\n")
 	/*
 	The below block of code commented out because the select statement will block until one of its cases is executed. In
 	this case, the select statement does not have any cases and hence it will block forever resulting in a deadlock.
@@ -1,8 +1,9 @@
 package concurrency
 
 import (
-	"log"
-	"math/rand"
+	"fmt"
+	"golang-tutorial/lessons"
+	"io"
 	"sync"
 	"time"
 )
@@ -28,7 +29,7 @@ func digits(number int) int {
 		sum += digit
 		no /= 10
 	}
-	time.Sleep(2 * time.Second)
+	lessons.Sleep(2 * time.Second)
 	return sum
 }
 
@@ -40,40 +41,40 @@ func worker(wg *sync.WaitGroup) {
 	wg.Done()
 }
 
-func createWorkerPool(noOfWorkers int) {
+func createWorkerPool(w io.Writer, noOfWorkers int) {
 	var wg sync.WaitGroup
 	for i := 0; i < noOfWorkers; i++ {
 		wg.Add(1)
 		go worker(&wg)
 	}
 	wg.Wait()
-	log.Println("Closing results channel...")
+	fmt.Fprintln(w, "Closing results channel...")
 	close(results)
 }
 
-func allocate(noOfJobs int) {
+func allocate(w io.Writer, noOfJobs int) {
 	for i := 0; i < noOfJobs; i++ {
-		randomNo := rand.Intn(999)
+		randomNo := lessons.Intn(999)
 		job := Job{
 			id:       i,
 			randomNo: randomNo,
 		}
-		log.Printf("Created job with %d and %d", job.id, job.randomNo)
+		fmt.Fprintf(w, "Created job with %d and %d\n", job.id, job.randomNo)
 		jobs <- job
 	}
-	log.Println("Closing jobs channel...")
+	fmt.Fprintln(w, "Closing jobs channel...")
 	close(jobs)
 }
 
-func result(done chan bool) {
+func result(w io.Writer, done chan bool) {
 	for result := range results {
-		log.Printf("Len results %d, Job id %d, input random no %d , sum of digits %d\n", len(results),
+		fmt.Fprintf(w, "Len results %d, Job id %d, input random no %d , sum of digits %d\n", len(results),
 			result.job.id, result.job.randomNo, result.sumOfDigits)
 	}
 	done <- true
 }
 
-func RunWorkerPoolsDemo() {
+func RunWorkerPoolsDemo(w io.Writer) {
 	/*
 	The following are the core functionalities of our worker pool:
 		- Creation of a pool of Goroutines which listen on an input buffered channel waiting for jobs to be assigned
@@ -81,15 +82,18 @@ func RunWorkerPoolsDemo() {
 		- Writing results to an output buffered channel after job completion
 		- Read and print results from the output buffered channel
 	*/
-	startTime := time.Now()
+	// Both channels are closed at the end of the demo, so they are recreated for every run of the lesson.
+	jobs = make(chan Job, 10)
+	results = make(chan Result, 10)
+	startTime := lessons.Now()
 	noOfJobs := 100
-	go allocate(noOfJobs)
+	go allocate(w, noOfJobs)
 	done := make(chan bool)
-	go result(done)
+	go result(w, done)
 	noOfWorkers := 100
-	createWorkerPool(noOfWorkers)
+	createWorkerPool(w, noOfWorkers)
 	<- done
-	endTime := time.Now()
+	endTime := lessons.Now()
 	diff := endTime.Sub(startTime)
-	log.Println("total time taken ", diff.Seconds(), "seconds")
+	fmt.Fprintln(w, "total time taken ", diff.Seconds(), "seconds")
 }
\ No newline at end of file
@@ -0,0 +1,7 @@
+package conditionals
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("conditionals", "conditionals", RunConditionals)
+}
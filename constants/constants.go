@@ -2,18 +2,19 @@ package constants
 
 import(
 	"fmt"
+	"io"
 	"math"
 )
 
-func RunConstants() {
-	fmt.Printf("\nBeginning of constants...\n")
+func RunConstants(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of constants...\n")
 	/*
 	The term constant is used in Go to denote fixed values such 5, -89, "I love Go", 67.89 and so on.
 	Constants as the name indicate cannot be reassigned again to any other value.
 	*/
 	var a int = 50
 	var b string = "I love Go"
-	fmt.Println("a=", a, " b=", b)
+	fmt.Fprintln(w, "a=", a, " b=", b)
 	/*
 	In the above code a and b are assigned to constants 50 and I love Go respectively. The keyword const is used to
 	denote constants such as 50 and I love Go. Even though we do not explicitly use the keyword const anywhere in the
@@ -27,7 +28,7 @@ func RunConstants() {
 	 */
 	var d = math.Sqrt(4) //allowed
 	// const e = math.Sqrt(4) //not allowed
-	fmt.Println("d=", d)
+	fmt.Fprintln(w, "d=", d)
 	/*
 	In the above program, a is a variable and hence it can be assigned to the result of the function math.Sqrt(4).
 	b is a constant and the value of b needs to be know at compile time. The function math.Sqrt(4) will be evaluated
@@ -35,7 +36,7 @@ func RunConstants() {
 	not a constant if we uncomment constant decleration.
 	 */
 
-	fmt.Printf("\nBeginning of string constants...\n")
+	fmt.Fprintf(w, "\nBeginning of string constants...\n")
 	/*
 	Any value enclosed between double quotes is a string constant in Go. What type does a string constant belong to?
 	The answer is they are untyped.
@@ -43,15 +44,15 @@ func RunConstants() {
 	// string constants like "Hello World" does not have a type
 	const hello  = "Hello World"
 	const hello1 = 12
-	fmt.Printf("type %T value %v", hello, hello)
-	fmt.Printf("type %T value %v", hello1, hello1)
+	fmt.Fprintf(w, "type %T value %v", hello, hello)
+	fmt.Fprintf(w, "type %T value %v", hello1, hello1)
 	/*
 	untyped constants have a default type associated with them and they supply it if and only if a line of code demands
 	it. In the statement var name = "Sam", name needs a type and it gets it from the default type of the string constant
 	"Sam" which is a string.
 	 */
 	const typedHello string = "Hello World"
-	fmt.Printf("type %T value %v", typedHello, typedHello)
+	fmt.Fprintf(w, "type %T value %v", typedHello, typedHello)
 	/*
 	Go is a strongly typed language. Mixing types during assignment is not allowed. Let's see what this means by the
 	help of a program.
@@ -64,9 +65,9 @@ func RunConstants() {
 	Even though we know that myString is an alias of string, Go's strong typing policy disallows variables of one type
 	to be assigned to another.
 	*/
-	fmt.Println(defaultName, customName)
+	fmt.Fprintln(w, defaultName, customName)
 
-	fmt.Printf("\nBeginning of boolean constants...\n")
+	fmt.Fprintf(w, "\nBeginning of boolean constants...\n")
 	/*
 	Boolean constants are no different from string constants. They are two untyped constants true and false.
 	*/
@@ -79,9 +80,9 @@ func RunConstants() {
 	Even though we know that myString is an alias of string, Go's strong typing policy disallows variables of one type
 	to be assigned to another.
 	*/
-	fmt.Println(defaultBool, customBool)
+	fmt.Fprintln(w, defaultBool, customBool)
 
-	fmt.Printf("\nBeginning of numeric constants...\n")
+	fmt.Fprintf(w, "\nBeginning of numeric constants...\n")
 	/*
 	Numeric constants include integers, floats and complex constants. 
 	 */
@@ -90,7 +91,7 @@ func RunConstants() {
 	var int32Var int32 = ab
 	var float64Var float64 = ab
 	var complex64Var complex64 = ab
-	fmt.Println("intVar",intVar, "\nint32Var", int32Var, "\nfloat64Var", float64Var, "\ncomplex64Var",complex64Var)
+	fmt.Fprintln(w, "intVar",intVar, "\nint32Var", int32Var, "\nfloat64Var", float64Var, "\ncomplex64Var",complex64Var)
 	/*
 	In this program, the value of a is 5 and the syntax of a is generic (it can represent a float, integer or even a
 	complex number with no imaginary part) and hence it is possible to be assigned to any compatible type. The default
@@ -99,12 +100,12 @@ func RunConstants() {
 	be a complex number and hence it becomes a complex constant.
 	*/
 
-	fmt.Printf("\nBeginning of numeric expressions...\n")
+	fmt.Fprintf(w, "\nBeginning of numeric expressions...\n")
 	/*
 	Numeric constants are free to be mixed and matched in expressions and a type is needed only when they are assigned
 	to variables or used in any place in code which demands a type.
 	 */
 	var abc = 5.9/8
 	abc = 5.8/7
-	fmt.Printf("a's type %T value %v",abc, abc)
+	fmt.Fprintf(w, "a's type %T value %v",abc, abc)
 }
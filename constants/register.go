@@ -0,0 +1,7 @@
+package constants
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("constants", "constants", RunConstants)
+}
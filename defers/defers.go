@@ -2,32 +2,33 @@ package defers
 
 import (
 	"fmt"
+	"io"
 	"sync"
 )
 
-func finished() {
-	fmt.Println("Finished finding largest")
+func finished(w io.Writer) {
+	fmt.Fprintln(w, "Finished finding largest")
 }
 
-func largest(nums []int) {
-	defer finished()
-	fmt.Println("Started finding largest")
+func largest(w io.Writer, nums []int) {
+	defer finished(w)
+	fmt.Fprintln(w, "Started finding largest")
 	max := nums[0]
 	for _, v := range nums {
 		if v > max {
 			max = v
 		}
 	}
-	fmt.Println("Largest number in", nums, "is", max)
+	fmt.Fprintln(w, "Largest number in", nums, "is", max)
 }
 
-func printA(a int) {
-	fmt.Println()
-	fmt.Println("value of a in deferred function", a)
+func printA(w io.Writer, a int) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "value of a in deferred function", a)
 }
 
-func RunDefers() {
-	fmt.Printf("\nBeginning of introduction...\n")
+func RunDefers(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction...\n")
 	/*
 	Defer statement is used to execute a function call just before the surrounding function where the defer statement
 	is present returns.
@@ -35,9 +36,9 @@ func RunDefers() {
 	line of largest function states it.
 	*/
 	nums := []int{68, 2394, 3, 1234, 4949}
-	largest(nums)
+	largest(w, nums)
 
-	fmt.Printf("\nBeginning of deferred methods...\n")
+	fmt.Fprintf(w, "\nBeginning of deferred methods...\n")
 	/*
 	Defer is not restricted only to functions. It is perfectly legal to defer a method call too.
 	*/
@@ -45,43 +46,43 @@ func RunDefers() {
 		firstName: "John",
 		lastName: "Smith",
 	}
-	defer p.fullName()
-	fmt.Printf("Welcome ")
+	defer p.fullName(w)
+	fmt.Fprintf(w, "Welcome ")
 
-	fmt.Printf("\nBeginning of arguments evaluation...\n")
+	fmt.Fprintf(w, "\nBeginning of arguments evaluation...\n")
 	/*
 	The arguments of a deferred function are evaluated when the defer statement is executed and not when the actual
 	function call is done.
 	*/
 	a := 5
-	defer printA(a)
+	defer printA(w, a)
 	a = 10
-	fmt.Println("value of a before deferred function call", a)
+	fmt.Fprintln(w, "value of a before deferred function call", a)
 	/*
 	Output of above program says that although the value of a changes to 10 after the defer statement is executed, the
 	actual deferred function call printA(a) still prints 5.
 	*/
 
-	fmt.Printf("\nBeginning of stack of defers...\n")
+	fmt.Fprintf(w, "\nBeginning of stack of defers...\n")
 	/*
 	When a function has multiple defer calls, they are pushed on to a stack and executed in Last In First Out (LIFO)
 	order.
 	*/
 	name := "Naveen"
-	fmt.Printf("Original String: %s\n", string(name))
-	fmt.Printf("Reversed String: ")
+	fmt.Fprintf(w, "Original String: %s\n", string(name))
+	fmt.Fprintf(w, "Reversed String: ")
 	for _, v := range []rune(name) {
-		defer fmt.Printf("%c", v)
+		defer fmt.Fprintf(w, "%c", v)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 	/*
-	In the program above, the for range loop in line no. 11, iterates the string and calls defer fmt.Printf("%c", v).
+	In the program above, the for range loop in line no. 11, iterates the string and calls defer fmt.Fprintf(w, "%c", v).
 	These deferred calls will be added to a stack.
 	The stack is a last in first out datastructure. The defer call that is pushed to the stack last will be pulled out
 	and executed first.
 	*/
 
-	fmt.Printf("\nBeginning of practical use of defer...\n")
+	fmt.Fprintf(w, "\nBeginning of practical use of defer...\n")
 	/*
 	Defer is used in places where a function call should be executed irrespective of the code flow.
 	Deferred function will be called just before the surrounding function returns.
@@ -94,10 +95,10 @@ func RunDefers() {
 	rects := []rect{r1, r2, r3, r4}
 	for _, v := range rects {
 		wg.Add(1)
-		go v.area(&wg)
+		go v.area(w, &wg)
 	}
 	wg.Wait()
-	fmt.Println("All go routines finished executing")
+	fmt.Fprintln(w, "All go routines finished executing")
 	/*
 	In the program above, we have removed the 3 wg.Done() calls in the area method of rect and replaced it with a single
 	defer wg.Done() call. This makes the code more simple and understandable.
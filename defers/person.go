@@ -1,12 +1,15 @@
 package defers
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 type person struct {
 	firstName string
 	lastName string
 }
 
-func (p person) fullName() {
-	fmt.Printf("%s %s",p.firstName,p.lastName)
+func (p person) fullName(w io.Writer) {
+	fmt.Fprintf(w, "%s %s",p.firstName,p.lastName)
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package defers
 
 import (
 	"fmt"
+	"io"
 	"sync"
 )
 
@@ -10,16 +11,16 @@ type rect struct {
 	width int
 }
 
-func (r rect) area(wg *sync.WaitGroup) {
+func (r rect) area(w io.Writer, wg *sync.WaitGroup) {
 	defer wg.Done()
 	if r.length < 0 {
-		fmt.Printf("rect %v's length should be greater than zero\n", r)
+		fmt.Fprintf(w, "rect %v's length should be greater than zero\n", r)
 		return
 	}
 	if r.width < 0 {
-		fmt.Printf("rect %v's width should be greater than zero\n", r)
+		fmt.Fprintf(w, "rect %v's width should be greater than zero\n", r)
 		return
 	}
 	area := r.length * r.width
-	fmt.Printf("rect %v's area %d\n", r, area)
+	fmt.Fprintf(w, "rect %v's area %d\n", r, area)
 }
@@ -0,0 +1,7 @@
+package defers
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("defers", "defers", RunDefers)
+}
@@ -1,6 +1,7 @@
 package error_handling
 
 import (
+	"io"
 	"errors"
 	"fmt"
 	"math"
@@ -50,8 +51,8 @@ func rectArea(length, width float64) (float64, error) {
 	return length * width, nil
 }
 
-func RunErrorHandling() {
-	fmt.Printf("\nBeginning of errors...\n")
+func RunErrorHandling(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of errors...\n")
 	/*
 	Errors in Go are plain old values. Errors are represented using the built-in error type.
 	If a function or method returns an error, then by convention it has to be the last value returned from the function.
@@ -61,12 +62,12 @@ func RunErrorHandling() {
 	file, err := os.Open("/test.txt")
 	if err != nil {
 		// "Error() string" method of error interface is just a string, so we can print it
-		fmt.Println(err)
+		fmt.Fprintln(w, err)
 	} else {
-		fmt.Println(file.Name(), "opened successfully")
+		fmt.Fprintln(w, file.Name(), "opened successfully")
 	}
 
-	fmt.Printf("\nBeginning of error type representation...\n")
+	fmt.Fprintf(w, "\nBeginning of error type representation...\n")
 	/*
 	error is an interface type with the following definition:
 		type error interface {
@@ -78,7 +79,7 @@ func RunErrorHandling() {
 	the error.
 	 */
 
-	fmt.Printf("\nBeginning of extracting more information from errors method 1...\n")
+	fmt.Fprintf(w, "\nBeginning of extracting more information from errors method 1...\n")
 	/*
 	Asserting the underlying struct type and getting more information from the struct fields.
 	If you read the documentation of the Open function carefully, you can see that it returns an error of type *PathError.
@@ -89,17 +90,17 @@ func RunErrorHandling() {
 	*/
 	f, err := os.Open("/test.txt")
 	if err, ok := err.(*os.PathError); ok {
-		fmt.Printf("type=%T, value=%s, ok=%v\n", err, err, ok)
-		fmt.Println("File at path", err.Path, "failed to open")
+		fmt.Fprintf(w, "type=%T, value=%s, ok=%v\n", err, err, ok)
+		fmt.Fprintln(w, "File at path", err.Path, "failed to open")
 	} else {
-		fmt.Println(f.Name(), "opened successfully")
+		fmt.Fprintln(w, f.Name(), "opened successfully")
 	}
 	/*
 	In the above program, we use type assertion to get the underlying value of the error interface. Then
 	we print the path using err.Path.
 	 */
 
-	fmt.Printf("\nBeginning of extracting more information from errors method 2...\n")
+	fmt.Fprintf(w, "\nBeginning of extracting more information from errors method 2...\n")
 	/*
 	The second way to get more information is to assert for the underlying type and get more information by calling
 	methods on the struct type.
@@ -109,22 +110,22 @@ func RunErrorHandling() {
 	 */
 	addr, err := net.LookupHost("golangbot123.com")
 	if err, ok := err.(*net.DNSError); ok {
-		fmt.Printf("type=%T, value=%s, ok=%v\n", err, err, ok)
+		fmt.Fprintf(w, "type=%T, value=%s, ok=%v\n", err, err, ok)
 		if err.Timeout() {
-			fmt.Println(err.Name)
-			fmt.Println("operation timed out")
+			fmt.Fprintln(w, err.Name)
+			fmt.Fprintln(w, "operation timed out")
 		} else if err.Temporary() {
-			fmt.Println(err.Name)
-			fmt.Println("temporary error")
+			fmt.Fprintln(w, err.Name)
+			fmt.Fprintln(w, "temporary error")
 		} else {
-			fmt.Println(err.Name)
-			fmt.Println("generic error: ", err)
+			fmt.Fprintln(w, err.Name)
+			fmt.Fprintln(w, "generic error: ", err)
 		}
 	} else if err == nil {
-		fmt.Println(addr)
+		fmt.Fprintln(w, addr)
 	}
 
-	fmt.Printf("\nBeginning of extracting more information from errors method 3...\n")
+	fmt.Fprintf(w, "\nBeginning of extracting more information from errors method 3...\n")
 	/*
 	The third way to get more details about an error is the direct comparison with a variable of type error.
 	The Glob function of the filepath package is used to return the names of all files that matches a pattern. This
@@ -136,32 +137,32 @@ func RunErrorHandling() {
 	 */
 	files, err := filepath.Glob("[")
 	if err != nil && err == filepath.ErrBadPattern {
-		fmt.Println(err)
-		fmt.Printf("type=%T, value=%s\n", err, err)
+		fmt.Fprintln(w, err)
+		fmt.Fprintf(w, "type=%T, value=%s\n", err, err)
 	} else if err == nil {
-		fmt.Println("matched files", files)
+		fmt.Fprintln(w, "matched files", files)
 	}
 
-	fmt.Printf("\nBeginning of ignoring errors...\n")
+	fmt.Fprintf(w, "\nBeginning of ignoring errors...\n")
 	/*
 	Never ever ignore an error. Ignoring errors is inviting for trouble.
 	 */
 	files2, _ := filepath.Glob("[")
-	fmt.Println("matched files", files2)
+	fmt.Fprintln(w, "matched files", files2)
 
-	fmt.Printf("\nBeginning of creating custom errors using the New function...\n")
+	fmt.Fprintf(w, "\nBeginning of creating custom errors using the New function...\n")
 	/*
 	The simplest way to create a custom error is to use the New function of the errors package.
 	 */
 	radius := -20.0
 	area, err := circleArea(radius)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(w, err)
 	} else {
-		fmt.Printf("Area of circle %0.2f\n", area)
+		fmt.Fprintf(w, "Area of circle %0.2f\n", area)
 	}
 
-	fmt.Printf("\nBeginning of adding more information to the errors using Errorf...\n")
+	fmt.Fprintf(w, "\nBeginning of adding more information to the errors using Errorf...\n")
 	/*
 	The above program works well but wouldn't it be nice if we print the actual radius which caused the error. This is
 	where the Errorf function of the fmt package comes in handy. This function formats the error according to a format
@@ -170,12 +171,12 @@ func RunErrorHandling() {
 	radius = -20.0
 	area, err = circleAreaWithErrorf(radius)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(w, err)
 	} else {
-		fmt.Printf("Area of circle %0.2f\n", area)
+		fmt.Fprintf(w, "Area of circle %0.2f\n", area)
 	}
 
-	fmt.Printf("\nBeginning of providing more information about the error using struct type and fields...\n")
+	fmt.Fprintf(w, "\nBeginning of providing more information about the error using struct type and fields...\n")
 	/*
 	It is also possible to use struct types which implement the error interface as errors. This gives us more
 	flexibility with error handling.
@@ -186,11 +187,11 @@ func RunErrorHandling() {
 	area, err = circleAreaWithCustomError(radius)
 	if err != nil {
 		if err, ok := err.(*areaError); ok {
-			fmt.Printf("Radius %0.2f is less than zero\n\n", err.radius)
+			fmt.Fprintf(w, "Radius %0.2f is less than zero\n\n", err.radius)
 		}
 	}
 
-	fmt.Printf("\nBeginning of providing more information about the error using methods on struct types...\n")
+	fmt.Fprintf(w, "\nBeginning of providing more information about the error using methods on struct types...\n")
 	/*
 	We have used methods on struct error types to provide more information about the error(check the struct on area_error2.go file).
 	Now that we have the error type, lets implement the error interface and add a couple of methods on the error type to
@@ -206,13 +207,13 @@ func RunErrorHandling() {
 	if err != nil {
 		if err, ok := err.(*areaError2); ok {
 			if err.lengthNegative() {
-				fmt.Printf("error: length %0.2f is less than zero\n", err.length)
+				fmt.Fprintf(w, "error: length %0.2f is less than zero\n", err.length)
 			}
 			if err.widthNegative() {
-				fmt.Printf("error: width %0.2f is less than zero\n", err.width)
+				fmt.Fprintf(w, "error: width %0.2f is less than zero\n", err.width)
 			}
 			return
 		}
 	}
-	fmt.Println("area of rect", area)
+	fmt.Fprintln(w, "area of rect", area)
 }
\ No newline at end of file
@@ -1,31 +1,34 @@
 package error_handling
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-func fullName(firstName *string, lastName *string) {
+func fullName(w io.Writer, firstName *string, lastName *string) {
 	if firstName == nil {
 		panic("runtime error: first name cannot be nil")
 	}
 	if lastName == nil {
 		panic("runtime error: last name cannot be nil")
 	}
-	fmt.Printf("%s %s\n", *firstName, *lastName)
-	fmt.Println("returned normally from fullName")
+	fmt.Fprintf(w, "%s %s\n", *firstName, *lastName)
+	fmt.Fprintln(w, "returned normally from fullName")
 }
 
-func deferredFullName(firstName *string, lastName *string) {
-	defer fmt.Println("deferred call in fullName")
+func deferredFullName(w io.Writer, firstName *string, lastName *string) {
+	defer fmt.Fprintln(w, "deferred call in fullName")
 	if firstName == nil {
 		panic("runtime error: first name cannot be nil")
 	}
 	if lastName == nil {
 		panic("runtime error: last name cannot be nil")
 	}
-	fmt.Printf("%s %s\n", *firstName, *lastName)
-	fmt.Println("returned normally from fullName")
+	fmt.Fprintf(w, "%s %s\n", *firstName, *lastName)
+	fmt.Fprintln(w, "returned normally from fullName")
 }
 
-func recoverName() {
+func recoverName(w io.Writer) {
 	/*
 	The recover built-in function allows a program to manage behavior of a
 	panicking goroutine. Executing a call to recover inside a deferred
@@ -38,24 +41,24 @@ func recoverName() {
 	panicking.
 	*/
 	if r := recover(); r != nil {
-		fmt.Println("recovered from", r)
+		fmt.Fprintln(w, "recovered from", r)
 	}
 }
 
-func recoverFullName(firstName *string, lastName *string) {
-	defer recoverName()
+func recoverFullName(w io.Writer, firstName *string, lastName *string) {
+	defer recoverName(w)
 	if firstName == nil {
 		panic("runtime error: first name cannot be nil")
 	}
 	if lastName == nil {
 		panic("runtime error: last name cannot be nil")
 	}
-	fmt.Printf("%s %s\n", *firstName, *lastName)
-	fmt.Println("returned normally from fullName")
+	fmt.Fprintf(w, "%s %s\n", *firstName, *lastName)
+	fmt.Fprintln(w, "returned normally from fullName")
 }
 
-func RunPanicAndRecover() {
-	fmt.Printf("\nBeginning of introduction...\n")
+func RunPanicAndRecover(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction...\n")
 	/*
 	The idiomatic way to handle abnormal conditions in a program in Go is using errors. Errors are sufficient for most
 	of the abnormal conditions arising in the program.
@@ -71,7 +74,7 @@ func RunPanicAndRecover() {
 	used and when used is more elegant and results in clean code.
 	 */
 
-	fmt.Printf("\nBeginning of when to use panic...\n")
+	fmt.Fprintf(w, "\nBeginning of when to use panic...\n")
 	/*
 	One important factor is that you should avoid panic and recover and use errors where ever possible. Only in cases
 	where the program just cannot continue execution should a panic and recover mechanism be used.
@@ -84,7 +87,7 @@ func RunPanicAndRecover() {
 		nil argument which was expecting a valid pointer.
 	 */
 
-	fmt.Printf("\nBeginning of panic example...\n")
+	fmt.Fprintf(w, "\nBeginning of panic example...\n")
 	/*
 	The signature of the built in panic function is provided like that:
 		func panic(interface{})
@@ -93,25 +96,25 @@ func RunPanicAndRecover() {
 	firstName := "Elon"
 	lastName := "Mask"
 	// uncomment below line to panic
-	//fullName(&firstName, nil)
-	fullName(&firstName, &lastName)
-	fmt.Println("returned normally from main")
+	//fullName(w, &firstName, nil)
+	fullName(w, &firstName, &lastName)
+	fmt.Fprintln(w, "returned normally from main")
 
-	fmt.Printf("\nBeginning of defer while panicking...\n")
+	fmt.Fprintf(w, "\nBeginning of defer while panicking...\n")
 	/*
 	When a function encounters a panic, its execution is stopped, any deferred functions are executed and then the
 	control returns to its caller. This process continues until all the functions of the current goroutine have
 	returned at which point the program prints the panic message, followed by the stack trace and then terminates.
 	 */
-	defer fmt.Println("first deferred call in main goroutine")
+	defer fmt.Fprintln(w, "first deferred call in main goroutine")
 	firstName = "Hasan"
 	lastName = "Huseyin"
 	// uncomment below line to panic. First deferred call on deferredFullName will be run, then the deferred call on main
 	// when deferredFullName function panics, any deferred function calls are first executed and then the control
 	// returns to the caller whose deferred calls are executed and so on until the top level caller is reached. Caller
 	// is the main goroutine in our case.
-	// deferredFullName(&firstName, nil)
-	fmt.Println("returned normally from main")
+	// deferredFullName(w, &firstName, nil)
+	fmt.Fprintln(w, "returned normally from main")
 	/*
 	When all the deferred calls are executed from down to up, control reaches the top level function and hence the
 	program prints the panic message followed by the stack trace and then terminates.
@@ -119,7 +122,7 @@ func RunPanicAndRecover() {
 	panic message.
 	 */
 
-	fmt.Printf("\nBeginning of recover...\n")
+	fmt.Fprintf(w, "\nBeginning of recover...\n")
 	/*
 	Recover is a builtin function which is used to regain control of a panicking goroutine. The signature of recover
 	function is like that:
@@ -128,10 +131,10 @@ func RunPanicAndRecover() {
 	function stops the panicking sequence by restoring normal execution and retrieves the error value passed to the
 	call of panic. If recover is called outside the deferred function, it will not stop a panicking sequence.
 	 */
-	defer fmt.Println("second deferred call in main goroutine")
+	defer fmt.Fprintln(w, "second deferred call in main goroutine")
 	firstName = "Elon"
-	recoverFullName(&firstName, nil)
-	fmt.Println("returned normally from main goroutine")
+	recoverFullName(w, &firstName, nil)
+	fmt.Fprintln(w, "returned normally from main goroutine")
 	/*
 	After execution of recover(), the panicking stops and the control returns to the caller, in this case the main
 	function and the program continues to execute normally from line 29 in main right after the panic.
@@ -2,58 +2,60 @@ package error_handling
 
 import (
 	"fmt"
+	"golang-tutorial/lessons"
+	"io"
 	"runtime/debug"
 	"time"
 )
 
-func recovery() {
+func recovery(w io.Writer) {
 	if r := recover(); r != nil {
-		fmt.Println("recovered:", r)
+		fmt.Fprintln(w, "recovered:", r)
 	}
 }
 
-func recoveryWithStackTrace() {
+func recoveryWithStackTrace(w io.Writer) {
 	if r := recover(); r != nil {
-		fmt.Println("recovered:", r)
-		debug.PrintStack()
+		fmt.Fprintln(w, "recovered:", r)
+		w.Write(debug.Stack())
 	}
 }
 
-func a() {
-	defer recovery()
-	fmt.Println("Inside A")
-	b()
-	// go b() // commented out to prevent runtime panic
-	time.Sleep(1 * time.Second)
+func a(w io.Writer) {
+	defer recovery(w)
+	fmt.Fprintln(w, "Inside A")
+	b(w)
+	// go b(w) // commented out to prevent runtime panic
+	lessons.Sleep(1 * time.Second)
 }
 
-func b() {
-	fmt.Println("Inside B")
+func b(w io.Writer) {
+	fmt.Fprintln(w, "Inside B")
 	panic("oh! B panicked!")
 }
 
-func c() {
-	defer recovery()
+func c(w io.Writer) {
+	defer recovery(w)
 	n := []int{5, 7, 4}
-	fmt.Println(n[3])
-	fmt.Println("normally returned from c")
+	fmt.Fprintln(w, n[3])
+	fmt.Fprintln(w, "normally returned from c")
 }
 
-func d() {
-	defer recoveryWithStackTrace()
+func d(w io.Writer) {
+	defer recoveryWithStackTrace(w)
 	n := []int{5, 7, 4}
-	fmt.Println(n[3]) // this line will cause to "runtime error: index out of range [3] with length 3"
-	fmt.Println("normally returned from c")
+	fmt.Fprintln(w, n[3]) // this line will cause to "runtime error: index out of range [3] with length 3"
+	fmt.Fprintln(w, "normally returned from c")
 }
 
-func RunPanicAndRecover2() {
-	fmt.Printf("\nBeginning of panic, recover and goroutines...\n")
+func RunPanicAndRecover2(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of panic, recover and goroutines...\n")
 	/*
 	Recover works only when it is called from the same goroutine. It's not possible to recover from a panic that has
 	happened in a different goroutine.
 	*/
-	a()
-	fmt.Println("normally returned from main goroutine")
+	a(w)
+	fmt.Fprintln(w, "normally returned from main goroutine")
 	/*
 	The panic will not be recovered. This is because the recovery function is present in a different goroutine(main
 	goroutine in that case) and the panic is happening in different goroutine(b goroutine in that case). Hence recovery
@@ -61,22 +63,22 @@ func RunPanicAndRecover2() {
 	also.
 	*/
 
-	fmt.Printf("\nBeginning of runtime panics...\n")
+	fmt.Fprintf(w, "\nBeginning of runtime panics...\n")
 	/*
 	Panics can also be caused by runtime errors such as array out of bounds access. This is equivalent to a call of
 	the built-in function panic with an argument defined by interface type runtime.Error.
 	If you comment out line 35, runtime panic will be occured.
 	 */
-	c()
-	fmt.Println("normally returned from main")
+	c(w)
+	fmt.Fprintln(w, "normally returned from main")
 
-	fmt.Printf("\nBeginning of getting stack trace after recover...\n")
+	fmt.Fprintf(w, "\nBeginning of getting stack trace after recover...\n")
 	/*
 	If we recover a panic, we loose the stack trace about the panic. Even in the program above after recovery, we lost
 	the stack trace. There is a way to print the stack trace using the PrintStack function of the Debug package.
 	 */
-	d()
-	fmt.Println("normally returned from main")
+	d(w)
+	fmt.Fprintln(w, "normally returned from main")
 	/*
 	From the output you can understand that first the panic is recovered and Recovered runtime error: index out of range
 	is printed. Following that the stack trace is printed. Then normally returned from main is printed after the panic
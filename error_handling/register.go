@@ -0,0 +1,9 @@
+package error_handling
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("error_handling", "error_handling", RunErrorHandling)
+	lessons.Register("error_handling", "panic_and_recover", RunPanicAndRecover)
+	lessons.Register("error_handling", "panic_and_recover2", RunPanicAndRecover2)
+}
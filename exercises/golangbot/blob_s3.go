@@ -0,0 +1,620 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minS3PartSize is the smallest size S3 accepts for every part of a multipart upload but the last.
+const minS3PartSize = 5 << 20
+
+/*
+s3BlobStore keeps blobs in a bucket of an S3 compatible object store. Objects can't be written at an offset, so a blob
+is assembled with a multipart upload while it is written and becomes a plain object named fileID once it is finished.
+
+Parts have to be partSize bytes except the last one and a PATCH hardly ever delivers exactly that. The bytes which
+don't fill a part yet are kept in the tail object fileID.part, which knows the offset it starts at. Every WriteAt
+first turns the committed bytes of the tail into parts and stores the rest of the tail together with the new bytes
+as the new tail. New bytes never go into a part before the next WriteAt committed them, so a chunk the uploader
+rejects, for example because of its checksum, can always be overwritten. The upload ID lives in fileID.info.
+*/
+type s3BlobStore struct {
+	endpoint  string // scheme and host of the object store, for example https://s3.eu-west-1.amazonaws.com
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	partSize  int64
+	client    *http.Client
+}
+
+type s3Info struct {
+	UploadID string `json:"upload_id"`
+}
+
+type s3Part struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
+// s3Error is an error response of the object store.
+type s3Error struct {
+	statusCode int
+	Code       string
+	Message    string
+}
+
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("s3: %d %s %s", e.statusCode, e.Code, e.Message)
+}
+
+func isS3NotFound(err error) bool {
+	se, ok := err.(*s3Error)
+	return ok && se.statusCode == http.StatusNotFound
+}
+
+func infoKey(fileID string) string {
+	return fileID + ".info"
+}
+
+func tailKey(fileID string) string {
+	return fileID + ".part"
+}
+
+/*
+do sends a signed request for the object key. A body of size 0 is sent as no body at all. Responses with an error
+status are turned into an *s3Error, otherwise the caller has to close the body of the response.
+*/
+func (s *s3BlobStore) do(method, key string, query url.Values, header http.Header, body io.Reader,
+	size int64) (*http.Response, error) {
+	u := s.endpoint + "/" + awsEscape(s.bucket, false) + "/" + awsEscape(key, false)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	if size == 0 {
+		body = nil
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	signV4(req, s.accessKey, s.secretKey, s.region, time.Now())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 300 {
+		defer res.Body.Close()
+		se := &s3Error{statusCode: res.StatusCode}
+		data, _ := ioutil.ReadAll(res.Body)
+		xml.Unmarshal(data, se)
+		return nil, se
+	}
+	return res, nil
+}
+
+// doXML sends a request and decodes the XML answer into v.
+func (s *s3BlobStore) doXML(method, key string, query url.Values, body []byte, v interface{}) error {
+	res, err := s.do(method, key, query, nil, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	// CompleteMultipartUpload may fail after it already answered 200, the error is in the body then.
+	var root struct {
+		XMLName xml.Name
+	}
+	if xml.Unmarshal(data, &root) == nil && root.XMLName.Local == "Error" {
+		se := &s3Error{statusCode: res.StatusCode}
+		xml.Unmarshal(data, se)
+		return se
+	}
+	return xml.Unmarshal(data, v)
+}
+
+func (s *s3BlobStore) put(key string, header http.Header, r io.Reader, size int64) error {
+	res, err := s.do("PUT", key, nil, header, r, size)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (s *s3BlobStore) delete(key string, query url.Values) error {
+	res, err := s.do("DELETE", key, query, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (s *s3BlobStore) head(key string) (http.Header, int64, error) {
+	res, err := s.do("HEAD", key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	res.Body.Close()
+	return res.Header, res.ContentLength, nil
+}
+
+// info returns the upload ID of the multipart upload of fileID, errBlobNotFound if there is none.
+func (s *s3BlobStore) info(fileID string) (s3Info, error) {
+	var info s3Info
+	res, err := s.do("GET", infoKey(fileID), nil, nil, nil, 0)
+	if isS3NotFound(err) {
+		return info, errBlobNotFound
+	}
+	if err != nil {
+		return info, err
+	}
+	defer res.Body.Close()
+	err = json.NewDecoder(res.Body).Decode(&info)
+	return info, err
+}
+
+func (s *s3BlobStore) createUpload(fileID string) (s3Info, error) {
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err := s.doXML("POST", fileID, url.Values{"uploads": {""}}, nil, &result)
+	if err != nil {
+		return s3Info{}, err
+	}
+	info := s3Info{UploadID: result.UploadID}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return s3Info{}, err
+	}
+	err = s.put(infoKey(fileID), nil, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return s3Info{}, err
+	}
+	return info, nil
+}
+
+func (s *s3BlobStore) listParts(fileID, uploadID string) ([]s3Part, error) {
+	var parts []s3Part
+	marker := ""
+	for {
+		var result struct {
+			Parts                []s3Part `xml:"Part"`
+			IsTruncated          bool
+			NextPartNumberMarker string
+		}
+		query := url.Values{"uploadId": {uploadID}}
+		if marker != "" {
+			query.Set("part-number-marker", marker)
+		}
+		err := s.doXML("GET", fileID, query, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, result.Parts...)
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (s *s3BlobStore) uploadPart(fileID, uploadID string, number int, r io.Reader, size int64) (s3Part, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	res, err := s.do("PUT", fileID, query, nil, r, size)
+	if err != nil {
+		return s3Part{}, err
+	}
+	res.Body.Close()
+	return s3Part{PartNumber: number, ETag: res.Header.Get("ETag"), Size: size}, nil
+}
+
+/*
+tail returns the offset the tail object of fileID starts at and its size. A missing tail starts right after the parts.
+*/
+func (s *s3BlobStore) tail(fileID string, partsSize int64) (int64, int64, error) {
+	header, size, err := s.head(tailKey(fileID))
+	if isS3NotFound(err) {
+		return partsSize, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	start, err := strconv.ParseInt(header.Get("X-Amz-Meta-Offset"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("tail of %s has no offset: %v", fileID, err)
+	}
+	return start, size, nil
+}
+
+// readTail copies the bytes from offset from up to offset to of the blob, which have to be in the tail, into w.
+func (s *s3BlobStore) readTail(fileID string, tailStart, from, to int64, w io.Writer) error {
+	if from >= to {
+		return nil
+	}
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", from-tailStart, to-tailStart-1)}}
+	res, err := s.do("GET", tailKey(fileID), nil, header, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	n, err := io.Copy(w, res.Body)
+	if err != nil {
+		return err
+	}
+	if n != to-from {
+		return fmt.Errorf("tail of %s is %d bytes short", fileID, to-from-n)
+	}
+	return nil
+}
+
+func partsSize(parts []s3Part) int64 {
+	var size int64
+	for _, p := range parts {
+		size += p.Size
+	}
+	return size
+}
+
+func (s *s3BlobStore) WriteAt(fileID string, r io.Reader, offset int64) (int64, error) {
+	info, err := s.info(fileID)
+	if err == errBlobNotFound {
+		info, err = s.createUpload(fileID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	parts, err := s.listParts(fileID, info.UploadID)
+	if err != nil {
+		return 0, err
+	}
+	uploaded := partsSize(parts)
+	if offset < uploaded {
+		return 0, fmt.Errorf("offset %d of %s is inside the %d bytes already uploaded as parts", offset, fileID,
+			uploaded)
+	}
+	tailStart, _, err := s.tail(fileID, uploaded)
+	if err != nil {
+		return 0, err
+	}
+
+	spool, err := ioutil.TempFile("", "s3blob")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	err = s.readTail(fileID, tailStart, uploaded, offset, spool)
+	if err != nil {
+		return 0, err
+	}
+	committed := offset - uploaded
+	n, rerr := io.Copy(spool, r)
+
+	for full := committed / s.partSize; full > 0; full-- {
+		p, err := s.uploadPart(fileID, info.UploadID, len(parts)+1, io.NewSectionReader(spool, partsSize(parts)-uploaded,
+			s.partSize), s.partSize)
+		if err != nil {
+			return 0, err
+		}
+		parts = append(parts, p)
+	}
+	start := partsSize(parts)
+	size := committed + n - (start - uploaded)
+	header := http.Header{"X-Amz-Meta-Offset": {strconv.FormatInt(start, 10)}}
+	err = s.put(tailKey(fileID), header, io.NewSectionReader(spool, start-uploaded, size), size)
+	if err != nil {
+		return 0, err
+	}
+	return n, rerr
+}
+
+func (s *s3BlobStore) Size(fileID string) (int64, error) {
+	info, err := s.info(fileID)
+	if err == errBlobNotFound {
+		_, size, err := s.head(fileID)
+		if isS3NotFound(err) {
+			return 0, nil
+		}
+		return size, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	parts, err := s.listParts(fileID, info.UploadID)
+	if err != nil {
+		return 0, err
+	}
+	uploaded := partsSize(parts)
+	tailStart, tailSize, err := s.tail(fileID, uploaded)
+	if err != nil {
+		return 0, err
+	}
+	if tailStart+tailSize > uploaded {
+		return tailStart + tailSize, nil
+	}
+	return uploaded, nil
+}
+
+/*
+Finish uploads the tail as the last part and completes the multipart upload. S3 refuses to complete an upload without
+parts, so an empty blob is written as an empty object instead.
+*/
+func (s *s3BlobStore) Finish(fileID string) error {
+	info, err := s.info(fileID)
+	if err == errBlobNotFound {
+		return s.put(fileID, nil, nil, 0)
+	}
+	if err != nil {
+		return err
+	}
+	parts, err := s.listParts(fileID, info.UploadID)
+	if err != nil {
+		return err
+	}
+	uploaded := partsSize(parts)
+	tailStart, tailSize, err := s.tail(fileID, uploaded)
+	if err != nil {
+		return err
+	}
+	if size := tailStart + tailSize - uploaded; size > 0 {
+		last, err := ioutil.TempFile("", "s3blob")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(last.Name())
+		defer last.Close()
+		err = s.readTail(fileID, tailStart, uploaded, tailStart+tailSize, last)
+		if err != nil {
+			return err
+		}
+		p, err := s.uploadPart(fileID, info.UploadID, len(parts)+1, io.NewSectionReader(last, 0, size), size)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, p)
+	}
+	if len(parts) == 0 {
+		err = s.delete(fileID, url.Values{"uploadId": {info.UploadID}})
+		if err == nil {
+			err = s.put(fileID, nil, nil, 0)
+		}
+	} else {
+		err = s.complete(fileID, info.UploadID, parts)
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.delete(tailKey(fileID), nil); err != nil {
+		return err
+	}
+	return s.delete(infoKey(fileID), nil)
+}
+
+func (s *s3BlobStore) complete(fileID, uploadID string, parts []s3Part) error {
+	type completedPart struct {
+		PartNumber int
+		ETag       string
+	}
+	var request struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}
+	for _, p := range parts {
+		request.Parts = append(request.Parts, completedPart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return err
+	}
+	var result struct{}
+	return s.doXML("POST", fileID, url.Values{"uploadId": {uploadID}}, body, &result)
+}
+
+// Open reads finished blobs only, the parts of an unfinished one can't be read back.
+func (s *s3BlobStore) Open(fileID string) (blobReader, error) {
+	_, size, err := s.head(fileID)
+	if isS3NotFound(err) {
+		return nil, errBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s3Reader{s: s, key: fileID, size: size}, nil
+}
+
+func (s *s3BlobStore) Delete(fileID string) error {
+	info, err := s.info(fileID)
+	if err != nil && err != errBlobNotFound {
+		return err
+	}
+	if err == nil {
+		err = s.delete(fileID, url.Values{"uploadId": {info.UploadID}})
+		if err != nil && !isS3NotFound(err) {
+			return err
+		}
+	}
+	for _, key := range []string{tailKey(fileID), infoKey(fileID), fileID} {
+		if err := s.delete(key, nil); err != nil && !isS3NotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+s3Reader reads an object with ranged GET requests. A request is only sent once Read is called and a Seek to another
+position drops it, so seeking around an object costs nothing until it is read.
+*/
+type s3Reader struct {
+	s    *s3BlobStore
+	key  string
+	size int64
+	pos  int64
+	body io.ReadCloser
+}
+
+func (sr *s3Reader) Read(p []byte) (int, error) {
+	if sr.pos >= sr.size {
+		return 0, io.EOF
+	}
+	if sr.body == nil {
+		header := http.Header{"Range": {fmt.Sprintf("bytes=%d-", sr.pos)}}
+		res, err := sr.s.do("GET", sr.key, nil, header, nil, 0)
+		if err != nil {
+			return 0, err
+		}
+		sr.body = res.Body
+	}
+	n, err := sr.body.Read(p)
+	sr.pos += int64(n)
+	if err == io.EOF && sr.pos < sr.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (sr *s3Reader) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case io.SeekCurrent:
+		pos += sr.pos
+	case io.SeekEnd:
+		pos += sr.size
+	}
+	if pos < 0 {
+		return 0, fmt.Errorf("seek to negative position %d", pos)
+	}
+	if pos != sr.pos {
+		sr.Close()
+		sr.pos = pos
+	}
+	return pos, nil
+}
+
+func (sr *s3Reader) Close() error {
+	if sr.body == nil {
+		return nil
+	}
+	err := sr.body.Close()
+	sr.body = nil
+	return err
+}
+
+/*
+signV4 signs req with AWS Signature Version 4, https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+Bodies are not hashed unless the caller set X-Amz-Content-Sha256 already, so they can be streamed.
+*/
+func signV4(req *http.Request, accessKey, secretKey, region string, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = "UNSIGNED-PAYLOAD"
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-amz-") || k == "range" || k == "content-type" || k == "content-md5" {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, awsEscape(k, true)+"="+awsEscape(v, true))
+		}
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsEscape(req.URL.Path, false),
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsEscape percent encodes everything but the unreserved characters and, unless encodeSlash is set, slashes.
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+)
+
+var errBlobNotFound = errors.New("blob not found")
+
+/*
+BlobStore keeps the bytes of uploads, the same way FileStore keeps their records. Blobs are named by fileID and come
+into existence with their first WriteAt.
+
+WriteAt writes r at offset and returns how many bytes were persisted, also when reading r fails halfway. Everything
+before offset is committed by the uploader. Whatever an earlier call wrote after it is dropped, the blob ends with the
+bytes written last. Size returns how many bytes a blob holds, 0 for a blob which was never written. Open returns
+errBlobNotFound for a blob which can't be read (yet) and Delete is no error for a blob which doesn't exist.
+*/
+type BlobStore interface {
+	WriteAt(fileID string, r io.Reader, offset int64) (int64, error)
+	Size(fileID string) (int64, error)
+	Open(fileID string) (blobReader, error)
+	Delete(fileID string) error
+}
+
+type blobReader interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+/*
+blobFinisher is implemented by blob stores which have to know when all bytes of a blob were written, for example to
+assemble it from parts. The uploader calls Finish before it marks an upload complete.
+*/
+type blobFinisher interface {
+	Finish(fileID string) error
+}
+
+// localBlobStore keeps every blob in a file named after its fileID in dir.
+type localBlobStore struct {
+	dir string
+}
+
+/*
+WriteAt syncs the file before returning, so the bytes counted are really on disk when the uploader commits the offset.
+*/
+func (ls localBlobStore) WriteAt(fileID string, r io.Reader, offset int64) (int64, error) {
+	f, err := os.OpenFile(path.Join(ls.dir, fileID), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	terr := f.Truncate(offset + n)
+	if err == nil {
+		err = terr
+	}
+	serr := f.Sync()
+	if err == nil {
+		err = serr
+	}
+	return n, err
+}
+
+func (ls localBlobStore) Size(fileID string) (int64, error) {
+	fi, err := os.Stat(path.Join(ls.dir, fileID))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (ls localBlobStore) Open(fileID string) (blobReader, error) {
+	f, err := os.Open(path.Join(ls.dir, fileID))
+	if os.IsNotExist(err) {
+		return nil, errBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (ls localBlobStore) Delete(fileID string) error {
+	err := os.Remove(path.Join(ls.dir, fileID))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
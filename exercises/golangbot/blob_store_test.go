@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func testBlobStore(t *testing.T, bs BlobStore) {
+	var writes = []struct {
+		offset int64
+		data   string
+		size   int64
+	}{
+		{0, "hello", 5},
+		{5, " world", 11},
+		// A chunk which is never committed, the next write at the same offset replaces it.
+		{11, "XXX", 14},
+		{11, "!", 12},
+	}
+	for _, w := range writes {
+		n, err := bs.WriteAt("1", strings.NewReader(w.data), w.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(w.data)) {
+			t.Errorf("For write of %q expected %d bytes got %d", w.data, len(w.data), n)
+		}
+		size, err := bs.Size("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != w.size {
+			t.Errorf("After write of %q at %d expected size %d got %d", w.data, w.offset, w.size, size)
+		}
+	}
+	if bf, ok := bs.(blobFinisher); ok {
+		if err := bf.Finish("1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	size, err := bs.Size("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 12 {
+		t.Errorf("For finished blob expected size 12 got %d", size)
+	}
+	br, err := bs.Open("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world!" {
+		t.Errorf("For finished blob expected %q got %q", "hello world!", data)
+	}
+	if _, err := br.Seek(6, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadAll(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "world!" {
+		t.Errorf("After seeking to 6 expected %q got %q", "world!", data)
+	}
+	br.Close()
+
+	n, err := bs.WriteAt("2", droppedReader{strings.NewReader("abc")}, 0)
+	if err == nil || n != 3 {
+		t.Errorf("For dropped write expected 3 bytes and an error got %d and %v", n, err)
+	}
+	size, err = bs.Size("2")
+	if err != nil || size != 3 {
+		t.Errorf("After dropped write expected size 3 got %d %v", size, err)
+	}
+
+	for _, fileID := range []string{"1", "2"} {
+		if err := bs.Delete(fileID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bs.Open(fileID); err != errBlobNotFound {
+			t.Errorf("For deleted blob %s expected %v got %v", fileID, errBlobNotFound, err)
+		}
+		if size, err := bs.Size(fileID); err != nil || size != 0 {
+			t.Errorf("For deleted blob %s expected size 0 got %d %v", fileID, size, err)
+		}
+	}
+	if err := bs.Delete("3"); err != nil {
+		t.Errorf("For delete of unknown blob expected no error got %v", err)
+	}
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	testBlobStore(t, localBlobStore{dir: dir})
+}
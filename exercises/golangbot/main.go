@@ -1,7 +1,13 @@
 package main
 
+import "os"
+
 func main() {
 	// Check https://golangbot.com/ for all
 	// https://golangbot.com/resumable-file-uploader/
+	if len(os.Args) > 1 && os.Args[1] == "upload" {
+		runUpload(os.Args[2:])
+		return
+	}
 	runResumableFileUploader()
 }
\ No newline at end of file
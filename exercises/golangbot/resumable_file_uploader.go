@@ -1,170 +1,308 @@
 package main
 
 import (
-	"database/sql"
+	"flag"
 	"fmt"
 	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
-	"io/ioutil"
+	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"os/user"
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 type file struct {
 	fileID         int
 	offset         *int
-	uploadLength   int
+	uploadLength   *int // nil while the length is deferred
 	uploadComplete *bool
+	uploadMetadata map[string]string
+	uploadConcat   string // Upload-Concat the upload was created with, empty for plain uploads
+	createdAt      time.Time // set by the store, when the upload was created
+	modifiedAt     time.Time // set by the store, when the upload was last changed
+	owner          string    // client the upload counts against in the quotas, see clientID
 }
 
 type fileHandler struct {
-	db      *sql.DB
-	dirPath string
+	store      FileStore
+	blobs      BlobStore
+	maxSize    int           // largest Upload-Length accepted, 0 for no limit
+	expiration time.Duration // how long an incomplete upload lives after its last change, 0 for forever
+	baseURL    string        // where the uploads are reachable without a trailing slash, empty to use the request
+	locks      *uploadLocks
+	hooks      *uploadHooks
+	quotas     *uploadQuotas
+	adminToken string // bearer token of the admin API, which is off if empty
 }
 
+const dirName = "fileserver"
+
+// Store backends the uploader can keep its upload records in, selected with the -store flag.
+const (
+	storeMemory   = "memory"
+	storeFile     = "file"
+	storePostgres = "postgres"
+)
+
+// Blob backends the uploader can keep uploaded bytes in, selected with the -blob flag.
 const (
-	dirName = "fileserver"
-	dbUser  = "postgres"
-	dbPwd   = "postgres"
-	dbName  = "fileserver"
-	sslMode = "disable"
+	blobLocal = "local"
+	blobS3    = "s3"
 )
 
-func (fh fileHandler) createTable() error {
-	q := `CREATE TABLE IF NOT EXISTS file(file_id SERIAL PRIMARY KEY, 
- 		  file_offset INT NOT NULL, file_upload_length INT NOT NULL, file_upload_complete BOOLEAN NOT NULL, 
-          created_at TIMESTAMP default NOW() NOT NULL, modified_at TIMESTAMP default NOW() NOT NULL)`
-	_, err := fh.db.Exec(q)
+func createFileDir() (string, error) {
+	u, err := user.Current()
 	if err != nil {
-		return err
+		log.Println("Error while fetching user home directory", err)
+		return "", err
 	}
-	return nil
-}
-
-func (fh fileHandler) createFile(f file) (string, error) {
-	cfstmt := `INSERT INTO file(file_offset, file_upload_length, file_upload_complete) VALUES($1, $2, $3) RETURNING file_id`
-	fileID := 0
-	err := fh.db.QueryRow(cfstmt, f.offset, f.uploadLength, f.uploadComplete).Scan(&fileID)
+	home := u.HomeDir
+	dirPath := path.Join(home, dirName)
+	err = os.MkdirAll(dirPath, 0744)
 	if err != nil {
+		log.Println("Error while creating file server directory", err)
 		return "", err
 	}
-	fid := strconv.Itoa(fileID)
-	return fid, nil
+	return dirPath, nil
 }
 
-func (fh fileHandler) updateFile(f file) error {
-	var query []string
-	var param []interface{}
-	if f.offset != nil {
-		of := fmt.Sprintf("file_offset = $1")
-		ofp := f.offset
-		query = append(query, of)
-		param = append(param, ofp)
-	}
-	if f.uploadComplete != nil {
-		uc := fmt.Sprintf("file_upload_complete = $2")
-		ucp := f.uploadComplete
-		query = append(query, uc)
-		param = append(param, ucp)
+/*
+openFileStore opens the store upload records are kept in. The file store defaults to a JSON file next to the uploaded
+files in dir, the postgres store connects to databaseDSN, which has no default so no credentials are built in.
+*/
+func openFileStore(storeType, storePath, databaseDSN, dir string) (FileStore, error) {
+	switch storeType {
+	case storeMemory:
+		return newMemoryStore(), nil
+	case storeFile:
+		if storePath == "" {
+			storePath = path.Join(dir, "uploads.json")
+		}
+		return openJSONFileStore(storePath)
+	case storePostgres:
+		if databaseDSN == "" {
+			return nil, fmt.Errorf("the postgres store needs a connection string, set -database-dsn")
+		}
+		return openPostgresStore(databaseDSN)
 	}
+	return nil, fmt.Errorf("unknown store %q", storeType)
+}
 
-	if len(query) > 0 {
-		mo := "modified_at = $3"
-		mop := "NOW()"
-
-		query = append(query, mo)
-		param = append(param, mop)
-
-		qj := strings.Join(query, ",")
-
-		sqlq := fmt.Sprintf("UPDATE file SET %s WHERE file_id = $4", qj)
-
-		param = append(param, f.fileID)
-
-		log.Println("generated update query", sqlq)
-		_, err := fh.db.Exec(sqlq, param...)
+/*
+openBlobStore opens the store uploaded bytes are kept in. The local store defaults to dir, s3 has to be configured
+with a bucket.
+*/
+func openBlobStore(blobType, blobDir, dir string, s3 *s3BlobStore) (BlobStore, error) {
+	switch blobType {
+	case blobLocal:
+		if blobDir == "" {
+			return localBlobStore{dir: dir}, nil
+		}
+		err := os.MkdirAll(blobDir, 0744)
 		if err != nil {
-			log.Println("Error during file update", err)
-			return err
+			return nil, err
+		}
+		return localBlobStore{dir: blobDir}, nil
+	case blobS3:
+		if s3.bucket == "" {
+			return nil, fmt.Errorf("the s3 blob store needs a bucket")
+		}
+		if s3.partSize < minS3PartSize {
+			return nil, fmt.Errorf("s3 part size %d is below the minimum %d", s3.partSize, minS3PartSize)
 		}
+		return s3, nil
 	}
-	return nil
+	return nil, fmt.Errorf("unknown blob store %q", blobType)
 }
 
-func (fh fileHandler) File(fileID string) (file, error) {
-	fID, err := strconv.Atoi(fileID)
-	if err != nil {
-		log.Println("Unable to convert fileID to string", err)
-		return file{}, err
-	}
-	log.Println("going to query for fileID", fID)
-	gfstmt := `select file_id, file_offset, file_upload_length, file_upload_complete from file where file_id = $1`
-	row := fh.db.QueryRow(gfstmt, fID)
-	f := file{}
-	err = row.Scan(&f.fileID, &f.offset, &f.uploadLength, &f.uploadComplete)
-	if err != nil {
-		log.Println("error while fetching file", err)
-		return file{}, err
+/*
+fileLocation returns the URL of the upload fileID, which the client sends its HEAD and PATCH requests to. Without a
+base URL it points to the host the request was sent to, behind a proxy the base URL has to be configured.
+*/
+func (fh fileHandler) fileLocation(r *http.Request, fileID string) string {
+	base := fh.baseURL
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
 	}
-	return f, nil
+	return fmt.Sprintf("%s/files/%s", base, fileID)
 }
 
-func createFileDir() (string, error) {
-	u, err := user.Current()
-	if err != nil {
-		log.Println("Error while fetching user home directory", err)
-		return "", err
+func (fh fileHandler) createFileHandler(w http.ResponseWriter, r *http.Request) {
+	concat := r.Header.Get(headerUploadConcat)
+	if strings.HasPrefix(concat, concatFinal) {
+		fh.createFinalUpload(w, r)
+		return
 	}
-	home := u.HomeDir
-	dirPath := path.Join(home, dirName)
-	err = os.MkdirAll(dirPath, 0744)
-	if err != nil {
-		log.Println("Error while creating file server directory", err)
-		return "", err
+	if concat != "" && concat != concatPartial {
+		e := "Improper upload concat"
+		log.Println(e)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(e))
+		return
 	}
-	return dirPath, nil
-}
-
-func (fh fileHandler) createFileHandler(w http.ResponseWriter, r *http.Request) {
-	ul, err := strconv.Atoi(r.Header.Get("Upload-Length"))
+	/*
+	With the creation-defer-length extension a client which doesn't know the size yet sends Upload-Defer-Length: 1
+	instead of Upload-Length and tells the length in a later PATCH.
+	*/
+	var uploadLength *int
+	if r.Header.Get(headerUploadDeferLength) != "" {
+		if r.Header.Get(headerUploadDeferLength) != "1" || r.Header.Get(headerUploadLength) != "" {
+			e := "Improper upload defer length"
+			log.Println(e)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(e))
+			return
+		}
+	} else {
+		ul, err := strconv.Atoi(r.Header.Get(headerUploadLength))
+		if err != nil || ul < 0 {
+			e := "Improper upload length"
+			log.Printf("%s %s", e, err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(e))
+			return
+		}
+		if fh.maxSize > 0 && ul > fh.maxSize {
+			e := fmt.Sprintf("Upload length %d exceeds the maximum size %d", ul, fh.maxSize)
+			log.Println(e)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(e))
+			return
+		}
+		uploadLength = &ul
+	}
+	metadata, err := parseMetadata(r.Header.Get(headerUploadMetadata))
 	if err != nil {
-		e := "Improper upload length"
+		e := "Improper upload metadata"
 		log.Printf("%s %s", e, err)
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(e))
 		return
 	}
-	log.Printf("upload length %d\n", ul)
-	io := 0
+	off := 0
 	uc := false
 	f := file{
-		offset:         &io,
-		uploadLength:   ul,
+		offset:         &off,
+		uploadLength:   uploadLength,
 		uploadComplete: &uc,
+		uploadMetadata: metadata,
+		uploadConcat:   concat,
+		owner:          fh.quotas.clientID(r),
 	}
-	fileID, err := fh.createFile(f)
-	if err != nil {
-		e := "Error creating file in DB"
-		log.Printf("%s %s\n", e, err)
-		w.WriteHeader(http.StatusInternalServerError)
+	withUpload := r.Header.Get(headerContentType) == tusOffsetContentType
+	remaining := fh.remaining(f)
+	if withUpload && f.uploadLength == nil {
+		// The quota counts only the offset of an upload with a deferred length, so its body is checked like a PATCH.
+		left, limited, err := fh.quotas.bytesLeft(fh.store, f.owner)
+		if err != nil {
+			log.Println("Error while checking quota", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if limited && r.ContentLength > left {
+			e := fmt.Sprintf("Upload exceeds the quota of the client, %d bytes are left", left)
+			log.Println(e)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(e))
+			return
+		}
+		if limited && left < remaining {
+			remaining = left
+		}
+	}
+	if withUpload && r.ContentLength > remaining {
+		e := fmt.Sprintf("Content length exceeds upload length. Expected at most %d bytes got %d", remaining,
+			r.ContentLength)
+		log.Println(e)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(e))
 		return
 	}
-
-	filePath := path.Join(fh.dirPath, fileID)
-	file, err := os.Create(filePath)
+	if !fh.checkQuota(w, f) {
+		return
+	}
+	err = fh.hooks.preCreate(newHookEvent(hookPreCreate, r, "", f))
 	if err != nil {
-		e := "Error creating file in filesystem"
-		log.Printf("%s %s\n", e, err)
-		w.WriteHeader(http.StatusInternalServerError)
+		rejectUpload(w, err)
 		return
 	}
-	defer file.Close()
-	w.Header().Set("Location", fmt.Sprintf("localhost:8080/files/%s", fileID))
+	log.Printf("upload length %s\n", r.Header.Get(headerUploadLength))
+	fileID, ok := fh.createFile(w, f)
+	if !ok {
+		return
+	}
+
+	/*
+	With the creation-with-upload extension the POST may already carry the first chunk of the file, which saves small
+	uploads the PATCH round trip. Whatever got persisted is committed like in filePatchHandler and the new offset is
+	returned, so the client continues from there with PATCH if the body was cut short. An empty upload is complete
+	right away, clients have no reason to send a PATCH for it. The upload is locked like for a PATCH, fileIDs are
+	sequential and a PATCH at offset 0 may come in before the POST is answered.
+	*/
+	empty := f.uploadLength != nil && *f.uploadLength == 0
+	if withUpload || empty {
+		unlock, ok := fh.locks.tryLock(fileID)
+		if !ok {
+			e := "Upload is locked by another request"
+			log.Println(e)
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(e))
+			return
+		}
+		defer unlock()
+		body := io.Reader(http.NoBody)
+		if withUpload {
+			body = io.LimitReader(r.Body, remaining)
+		}
+		n, err := fh.blobs.WriteAt(fileID, body, 0)
+		if err != nil {
+			log.Printf("Received file partially %s\n", err)
+		}
+		no := int(n)
+		f.offset = &no
+		if f.uploadLength != nil && no == *f.uploadLength {
+			err = fh.finish(fileID)
+			if err != nil {
+				log.Println("Error while finishing file", err)
+				fh.discardUpload(fileID)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			*f.uploadComplete = true
+		}
+		f.fileID, _ = strconv.Atoi(fileID)
+		uerr := fh.store.UpdateFileAt(f, 0)
+		if uerr == errOffsetMismatch {
+			e := "Upload offset was changed by another request"
+			log.Println(e)
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(e))
+			return
+		}
+		if uerr != nil {
+			log.Println("Error while updating file", uerr)
+			fh.discardUpload(fileID)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(headerUploadOffset, strconv.Itoa(no))
+		fh.notifyCommitted(r, fileID, f, n)
+	}
+	w.Header().Set(headerLocation, fh.fileLocation(r, fileID))
+	f.modifiedAt = time.Now()
+	setUploadExpires(w, fh.expires(f))
 	w.WriteHeader(http.StatusCreated)
 	return
 }
@@ -172,33 +310,90 @@ func (fh fileHandler) createFileHandler(w http.ResponseWriter, r *http.Request)
 func (fh fileHandler) fileDetailsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	fID := vars["fileID"]
-	file, err := fh.File(fID)
-	if err != nil {
+	file, err := fh.store.File(fID)
+	if err == errFileNotFound {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		log.Println("Error while fetching file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if fh.expired(file, time.Now()) {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
 	log.Println("going to write upload offset to output")
-	w.Header().Set("Upload-Offset", strconv.Itoa(*file.offset))
+	w.Header().Set(headerUploadOffset, strconv.Itoa(*file.offset))
+	if file.uploadLength != nil {
+		w.Header().Set(headerUploadLength, strconv.Itoa(*file.uploadLength))
+	} else {
+		w.Header().Set(headerUploadDeferLength, "1")
+	}
+	if len(file.uploadMetadata) > 0 {
+		w.Header().Set(headerUploadMetadata, encodeMetadata(file.uploadMetadata))
+	}
+	if file.uploadConcat != "" {
+		w.Header().Set(headerUploadConcat, file.uploadConcat)
+	}
+	setUploadExpires(w, fh.expires(file))
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
 	w.WriteHeader(http.StatusOK)
 	return
 }
 
 func (fh fileHandler) filePatchHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("going to patch file")
+	if r.Header.Get(headerContentType) != tusOffsetContentType {
+		e := fmt.Sprintf("Content-Type must be %s", tusOffsetContentType)
+		log.Println(e)
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		w.Write([]byte(e))
+		return
+	}
 	vars := mux.Vars(r)
 	fID := vars["fileID"]
-	file, err := fh.File(fID)
-	if err != nil {
+	/*
+	Only one PATCH at a time may write to an upload. A second one would read the same offset and write over the bytes
+	of the first, so it is turned away with 409 Conflict and the client asks for the offset again with HEAD.
+	*/
+	unlock, ok := fh.locks.tryLock(fID)
+	if !ok {
+		e := "Upload is locked by another request"
+		log.Println(e)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(e))
+		return
+	}
+	defer unlock()
+	file, err := fh.store.File(fID)
+	if err == errFileNotFound {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		log.Println("Error while fetching file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if fh.expired(file, time.Now()) {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+	if strings.HasPrefix(file.uploadConcat, concatFinal) {
+		e := "Final uploads can't be patched"
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(e))
+		return
+	}
 	if *file.uploadComplete == true {
 		e := "Upload already completed"
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		w.Write([]byte(e))
 		return
 	}
-	off, err := strconv.Atoi(r.Header.Get("Upload-Offset"))
+	off, err := strconv.Atoi(r.Header.Get(headerUploadOffset))
 	if err != nil {
 		log.Println("Improper upload offset", err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -212,66 +407,226 @@ func (fh fileHandler) filePatchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("Content length is", r.Header.Get("Content-Length"))
-	clh := r.Header.Get("Content-Length")
-	cl, err := strconv.Atoi(clh)
-	if err != nil {
-		log.Println("unknown content length")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	// A deferred length may be set by any PATCH, but only once. It can't be smaller than what was already received.
+	deferred := file.uploadLength == nil
+	if deferred && r.Header.Get(headerUploadLength) != "" {
+		ul, err := strconv.Atoi(r.Header.Get(headerUploadLength))
+		if err != nil || ul < *file.offset {
+			e := "Improper upload length"
+			log.Printf("%s %s", e, err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(e))
+			return
+		}
+		if fh.maxSize > 0 && ul > fh.maxSize {
+			e := fmt.Sprintf("Upload length %d exceeds the maximum size %d", ul, fh.maxSize)
+			log.Println(e)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(e))
+			return
+		}
+		file.uploadLength = &ul
 	}
 
-	if cl != (file.uploadLength - *file.offset) {
-		e := fmt.Sprintf("Content length doesn't not match upload length.Expected content length %d got %d", file.uploadLength-*file.offset, cl)
+	remaining := fh.remaining(file)
+
+	/*
+	The bytes quota of the owner counted only what an upload with a deferred length had received so far. Setting the
+	length reserves the rest, otherwise the chunk itself has to fit into the quota.
+	*/
+	if deferred {
+		left, limited, err := fh.quotas.bytesLeft(fh.store, file.owner)
+		if err != nil {
+			log.Println("Error while checking quota", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		needed := r.ContentLength
+		if file.uploadLength != nil {
+			needed = int64(*file.uploadLength - *file.offset)
+		}
+		if limited && needed > left {
+			e := fmt.Sprintf("Upload exceeds the quota of the client, %d bytes are left", left)
+			log.Println(e)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(e))
+			return
+		}
+		if limited && left < remaining {
+			remaining = left
+		}
+	}
+	log.Println("Content length is", r.ContentLength)
+	if r.ContentLength > remaining {
+		e := fmt.Sprintf("Content length exceeds upload length. Expected at most %d bytes got %d", remaining,
+			r.ContentLength)
 		log.Println(e)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(e))
+		return
+	}
+
+	checksum, err := parseChecksum(r.Header.Get(headerUploadChecksum))
+	if err != nil {
+		e := "Improper upload checksum"
+		log.Printf("%s %s", e, err)
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(e))
 		return
 	}
 
-	body, err := ioutil.ReadAll(r.Body)
+	/*
+	The body may be any chunk of the remaining bytes and it is streamed to disk instead of being read into memory
+	first. If the connection drops halfway, n still holds the number of bytes that made it to disk and only those are
+	committed, so the client can resume right after them.
+	*/
+	var body io.Reader = io.LimitReader(r.Body, remaining)
+	if checksum != nil {
+		body = io.TeeReader(body, checksum.hash)
+	}
+	n, err := fh.blobs.WriteAt(fID, body, int64(off))
+	log.Println("number of bytes written ", n)
 	if err != nil {
 		log.Printf("Received file partially %s\n", err)
-		log.Println("Size of received file ", len(body))
 	}
-	fp := fmt.Sprintf("%s/%s", fh.dirPath, fID)
-	f, err := os.OpenFile(fp, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Printf("unable to open file %s\n", err)
+	if n == 0 && err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	defer f.Close()
 
-	n, err := f.WriteAt(body, int64(off))
-	if err != nil {
-		log.Printf("unable to write %s", err)
+	/*
+	A chunk with a checksum is committed whole or not at all: a partial body can't be verified and a mismatch means
+	the bytes got corrupted on the way. Either way the stored offset stays where it was and the client sends the chunk
+	again, overwriting whatever was written past the offset.
+	*/
+	if checksum != nil && err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	log.Println("number of bytes written ", n)
-	no := *file.offset + n
+	if checksum != nil && !checksum.matches() {
+		e := "Checksum of the chunk doesn't match Upload-Checksum"
+		log.Println(e)
+		w.WriteHeader(statusChecksumMismatch)
+		w.Write([]byte(e))
+		return
+	}
+	no := *file.offset + int(n)
 	file.offset = &no
 
 	uo := strconv.Itoa(*file.offset)
-	w.Header().Set("Upload-Offset", uo)
-	if *file.offset == file.uploadLength {
+	w.Header().Set(headerUploadOffset, uo)
+	if file.uploadLength != nil && *file.offset == *file.uploadLength && err == nil {
+		ferr := fh.finish(fID)
+		if ferr != nil {
+			log.Println("Error while finishing file", ferr)
+			w.Header().Del(headerUploadOffset)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 		log.Println("upload completed successfully")
 		*file.uploadComplete = true
 	}
 
-	err = fh.updateFile(file)
+	uerr := fh.store.UpdateFileAt(file, off)
+	if uerr == errOffsetMismatch {
+		e := "Upload offset was changed by another request"
+		log.Println(e)
+		w.Header().Del(headerUploadOffset)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(e))
+		return
+	}
+	if uerr == errFileNotFound {
+		w.Header().Del(headerUploadOffset)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if uerr != nil {
+		log.Println("Error while updating file", uerr)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	fh.notifyCommitted(r, fID, file, n)
 	if err != nil {
-		log.Println("Error while updating file", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	file.modifiedAt = time.Now()
+	setUploadExpires(w, fh.expires(file))
 	w.WriteHeader(http.StatusNoContent)
 
 	return
 
 }
 
+/*
+remaining returns how many more bytes f accepts. While its length is deferred that is bounded only by the maximum
+size of the uploader.
+*/
+func (fh fileHandler) remaining(f file) int64 {
+	if f.uploadLength != nil {
+		return int64(*f.uploadLength - *f.offset)
+	}
+	if fh.maxSize > 0 {
+		return int64(fh.maxSize - *f.offset)
+	}
+	return math.MaxInt64
+}
+
+/*
+notifyCommitted runs the post-receive hooks of an upload n bytes were committed to and its post-finish hooks if that
+completed it.
+*/
+func (fh fileHandler) notifyCommitted(r *http.Request, fileID string, f file, n int64) {
+	var events []hookEvent
+	if n > 0 {
+		events = append(events, newHookEvent(hookPostReceive, r, fileID, f))
+	}
+	if *f.uploadComplete {
+		events = append(events, newHookEvent(hookPostFinish, r, fileID, f))
+	}
+	if len(events) > 0 {
+		fh.hooks.notify(events...)
+	}
+}
+
+// finish tells blob stores which assemble their blobs that all bytes of fileID were written.
+func (fh fileHandler) finish(fileID string) error {
+	if bf, ok := fh.blobs.(blobFinisher); ok {
+		return bf.Finish(fileID)
+	}
+	return nil
+}
+
+/*
+fileDeleteHandler implements the termination extension. The record goes first so no PATCH can write to the upload any
+more, then the blob is deleted to give its space back.
+*/
+func (fh fileHandler) fileDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	fID := vars["fileID"]
+	unlock, ok := fh.locks.tryLock(fID)
+	if !ok {
+		e := "Upload is locked by another request"
+		log.Println(e)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(e))
+		return
+	}
+	defer unlock()
+	err := fh.deleteUpload(fID)
+	if err == errFileNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error while deleting file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func runResumableFileUploader() {
 	/*
 	Tus is a new open protocol for resumable uploads built on HTTP. https://tus.io/
@@ -308,35 +663,164 @@ func runResumableFileUploader() {
 			by PATCH.
 	The gist is to keep calling HEAD to know the current Upload-Offset followed by PATCH until the server responds with
 	a Upload-Offset equal to Upload-Length.
+	On top of that the tus 1.0.0 core protocol requires:
+		- A Tus-Resumable: 1.0.0 header on every request and response. Requests asking for another version are
+		answered with 412 Precondition Failed and a Tus-Version header listing the versions the server speaks.
+		- An OPTIONS request which lets clients discover the server's Tus-Version, Tus-Extension and Tus-Max-Size.
+		- PATCH bodies sent as Content-Type: application/offset+octet-stream, anything else gets 415.
+		- HEAD responses carrying Upload-Length next to Upload-Offset and Cache-Control: no-store, so no proxy serves
+		a stale offset.
+	Only one PATCH or DELETE at a time may work on an upload, a concurrent one is answered with 409 Conflict. On top of
+	that the offset is committed only if it is still the one the PATCH started from, so uploader instances sharing one
+	Postgres database never both commit a chunk for the same range.
+	The uploader also supports these extensions:
+		- creation-with-upload: a POST with Content-Type: application/offset+octet-stream carries the first bytes of
+		the file and its response contains the resulting Upload-Offset.
+		- termination: DELETE /files/{fileID} aborts an upload, removing its record and the file on disk.
+		- creation-defer-length: a POST may send Upload-Defer-Length: 1 instead of Upload-Length when the size is not
+		known yet. Any later PATCH can set Upload-Length and until then HEAD answers with Upload-Defer-Length: 1.
+		- checksum: a PATCH may carry Upload-Checksum, the name of a hash algorithm and the base64 encoded digest of
+		the body. The chunk is only committed if the digest matches, otherwise the server answers 460 Checksum
+		Mismatch and the offset stays unchanged.
+		- expiration: when started with -expiration, incomplete uploads expire that long after their last change.
+		Responses tell the time in Upload-Expires, requests for an expired upload get 410 Gone and a janitor deletes
+		expired uploads from the stores every -janitor-interval.
+		- concatenation: a file can be uploaded in parallel as several uploads created with Upload-Concat: partial.
+		Once they are complete a POST with Upload-Concat: final;/files/1 /files/2 stitches them together into a new,
+		complete upload. Final uploads can't be patched.
+	The uploaded bytes are kept in a directory, by default ~/fileserver, or with -blob s3 in a bucket of an S3
+	compatible object store. The credentials of the object store are read from AWS_ACCESS_KEY_ID and
+	AWS_SECRET_ACCESS_KEY.
+	Upload records are kept in a JSON file next to the uploaded bytes, or with -store in memory or in Postgres,
+	reached with -database-dsn. The server listens on -listen and hands out upload URLs below -base-url, or below the host a request was sent to if
+	no base URL is set. Every flag can also be set with an environment variable, UPLOADER_ and the flag name in upper
+	case, or in the JSON file named by -config.
+	Upload-Metadata sent with the POST, comma separated pairs of a key and a base64 encoded value, is stored with the
+	upload and returned on HEAD, so the original filename and content type are not lost.
+	Completed uploads can be downloaded with GET /files/{fileID}, which supports Range requests. The filename and
+	filetype metadata become the filename and Content-Type of the download. Uploads still in progress are 423 Locked.
+	Hooks let other programs react to uploads: a pre-create hook runs before an upload is created and may reject it
+	with a status of its own, post-receive hooks run whenever bytes were committed and post-finish hooks once an
+	upload is complete, for example to scan or move the file. They are Go functions, a -hook-command run with the
+	hook type as argument and the event as JSON on stdin, or a -hook-url the event is posted to.
+	Every client is limited by quotas: -max-size is the largest upload, advertised as Tus-Max-Size, and
+	-max-bytes-per-client what all uploads of a client may hold together, both answered with 413 Request Entity Too
+	Large when exceeded. -max-uploads-per-client is how many incomplete uploads a client may have, more get 429 Too
+	Many Requests. Clients are told apart by their IP address or by the identity an authenticating proxy puts into
+	the -identity-header.
+	With an -admin-token the admin API is on, requests to it need the token as bearer token:
+		- GET /admin/uploads lists the uploads as JSON with their progress in percent, 50 per page or ?limit. The
+		next page is ?after=<next> with next taken from the response. ?status=complete or incomplete,
+		?created_before=<RFC 3339 time> and ?filename=<part of the filename> filter the list.
+		- DELETE /admin/uploads/{fileID} deletes an upload, complete or not.
+	On SIGINT or SIGTERM the server stops accepting connections and gives requests in flight -shutdown-timeout to
+	finish. PATCHes still sending after that are cut off and commit the bytes they received, so their clients resume
+	from there, and then the store is closed. A second signal exits without waiting.
+	The tusclient package is a client of the uploader, golangbot upload <file> <url> uploads a file with it. Dropped
+	chunks are recovered with HEAD and retried, and an interrupted upload is resumed when the command runs again.
 	*/
-	connStr := fmt.Sprintf("user=%s password=%s dbname=%s sslmode=%s", dbUser, dbPwd, dbName, sslMode)
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		log.Fatal(err)
+	cfg, err := loadConfig(os.Args[1:], os.Getenv)
+	if err == flag.ErrHelp {
+		return
 	}
-	err = db.Ping()
 	if err != nil {
-		log.Fatal(err)
+		log.Fatal("Error reading configuration ", err)
 	}
-	log.Println("Connection established successfully")
-	log.Println("TUS Server started")
-	fh := fileHandler{
-		db: db,
+	s3 := s3BlobStore{
+		endpoint:  cfg.s3Endpoint,
+		bucket:    cfg.s3Bucket,
+		region:    cfg.s3Region,
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		partSize:  cfg.s3PartSize,
+		client:    http.DefaultClient,
 	}
+
 	dir, err := createFileDir()
 	if err != nil {
 		log.Fatal("Error creating file server directory", err)
 	}
-	fh.dirPath = dir
 	log.Println("Directory created successfully")
-	err = fh.createTable()
+	store, err := openFileStore(cfg.storeType, cfg.storePath, cfg.databaseDSN, dir)
+	if err != nil {
+		log.Fatal("Error opening file store ", err)
+	}
+	blobs, err := openBlobStore(cfg.blobType, cfg.blobDir, dir, &s3)
+	if err != nil {
+		log.Fatal("Error opening blob store ", err)
+	}
+	log.Println("TUS Server started")
+	fh := fileHandler{
+		store:      store,
+		blobs:      blobs,
+		maxSize:    cfg.maxSize,
+		expiration: cfg.expiration,
+		baseURL:    strings.TrimSuffix(cfg.baseURL, "/"),
+		locks:      newUploadLocks(),
+		hooks:      newUploadHooks(),
+		quotas: &uploadQuotas{
+			maxUploads:     cfg.maxUploads,
+			maxBytes:       cfg.maxBytes,
+			identityHeader: cfg.identityHeader,
+		},
+		adminToken: cfg.adminToken,
+	}
+	for _, hookType := range []string{hookPreCreate, hookPostReceive, hookPostFinish} {
+		if cfg.hookCommand != "" {
+			fh.hooks.add(hookType, commandHook{path: cfg.hookCommand})
+		}
+		if cfg.hookURL != "" {
+			fh.hooks.add(hookType, webhook{url: cfg.hookURL, client: &http.Client{Timeout: hookTimeout}})
+		}
+	}
+	/*
+	SIGINT or SIGTERM, as sent by a deploy, stops the janitor and shuts the server down gracefully. The store is closed
+	only after the last request, the janitor and the hooks are done with it. A second signal exits right away.
+	*/
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go stopOnSignals(signals, stop, os.Exit)
+	janitorDone := make(chan struct{})
+	go func() {
+		fh.runJanitor(cfg.janitorInterval, stop)
+		close(janitorDone)
+	}()
+	l, err := net.Listen("tcp", cfg.listen)
 	if err != nil {
-		log.Fatal("Error during table creation", err)
+		log.Fatal("Error listening ", err)
 	}
-	log.Println("table created successfully")
+	log.Println("Listening on", cfg.listen)
+	err = serveUntil(&http.Server{Handler: fh.router()}, l, stop, cfg.shutdownTimeout)
+	if err != nil {
+		log.Fatal("Error serving ", err)
+	}
+	<-janitorDone
+	fh.hooks.wait()
+	err = store.Close()
+	if err != nil {
+		log.Fatal("Error closing file store ", err)
+	}
+	log.Println("TUS Server stopped")
+}
+
+func (fh fileHandler) router() *mux.Router {
 	r := mux.NewRouter()
-	r.HandleFunc("/files", fh.createFileHandler).Methods("POST")
-	r.HandleFunc("/files/{fileID:[0-9]+}", fh.fileDetailsHandler).Methods("HEAD")
-	r.HandleFunc("/files/{fileID:[0-9]+}", fh.filePatchHandler).Methods("PATCH")
-	http.ListenAndServe(":8080", r)
+	// The admin API is no tus endpoint, so it lives outside the subrouter which checks Tus-Resumable.
+	if fh.adminToken != "" {
+		admin := r.PathPrefix("/admin").Subrouter()
+		admin.Use(fh.adminAuth)
+		admin.HandleFunc("/uploads", fh.adminListHandler).Methods("GET")
+		admin.HandleFunc("/uploads/{fileID:[0-9]+}", fh.adminDeleteHandler).Methods("DELETE")
+	}
+	tus := r.NewRoute().Subrouter()
+	tus.Use(tusResumable)
+	tus.HandleFunc("/files", fh.optionsHandler).Methods("OPTIONS")
+	tus.HandleFunc("/files/{fileID:[0-9]+}", fh.optionsHandler).Methods("OPTIONS")
+	tus.HandleFunc("/files", fh.createFileHandler).Methods("POST")
+	tus.HandleFunc("/files/{fileID:[0-9]+}", fh.fileDetailsHandler).Methods("HEAD")
+	tus.HandleFunc("/files/{fileID:[0-9]+}", fh.filePatchHandler).Methods("PATCH")
+	tus.HandleFunc("/files/{fileID:[0-9]+}", fh.fileDeleteHandler).Methods("DELETE")
+	tus.HandleFunc("/files/{fileID:[0-9]+}", fh.fileDownloadHandler).Methods("GET")
+	return r
 }
\ No newline at end of file
@@ -0,0 +1,908 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-tutorial/exercises/golangbot/tusclient"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestHandler returns an uploader which keeps its records in memory and its files in a temporary directory.
+func newTestHandler(t *testing.T) fileHandler {
+	dir, err := ioutil.TempDir("", "fileserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return fileHandler{store: newMemoryStore(), blobs: localBlobStore{dir: dir}, locks: newUploadLocks()}
+}
+
+func readBlob(fh fileHandler, fileID string) ([]byte, error) {
+	br, err := fh.blobs.Open(fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer br.Close()
+	return ioutil.ReadAll(br)
+}
+
+func serve(t *testing.T, fh fileHandler) *httptest.Server {
+	ts := httptest.NewServer(fh.router())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+/*
+doRequest sends a request the way a tus client would: with the Tus-Resumable header and, for PATCH, the offset content
+type. Both can be overridden through header, an empty value removes them.
+*/
+func doRequest(t *testing.T, method, url string, header map[string]string, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(headerTusResumable, tusVersion)
+	if method == "PATCH" {
+		req.Header.Set(headerContentType, tusOffsetContentType)
+	}
+	for k, v := range header {
+		if v == "" {
+			req.Header.Del(k)
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func TestResumableUpload(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fileURL := ts.URL + "/files/1"
+
+	res = doRequest(t, "HEAD", fileURL, nil, "")
+	if res.StatusCode != http.StatusOK || res.Header.Get("Upload-Offset") != "0" {
+		t.Fatalf("For HEAD expected status %d offset 0 got %d offset %q", http.StatusOK, res.StatusCode,
+			res.Header.Get("Upload-Offset"))
+	}
+	if res.Header.Get("Upload-Length") != "11" || res.Header.Get("Cache-Control") != "no-store" {
+		t.Errorf("For HEAD expected length 11 and no-store got length %q and %q", res.Header.Get("Upload-Length"),
+			res.Header.Get("Cache-Control"))
+	}
+
+	res = doRequest(t, "PATCH", fileURL, map[string]string{"Upload-Offset": "0"}, "hello world")
+	if res.StatusCode != http.StatusNoContent || res.Header.Get("Upload-Offset") != "11" {
+		t.Fatalf("For PATCH expected status %d offset 11 got %d offset %q", http.StatusNoContent, res.StatusCode,
+			res.Header.Get("Upload-Offset"))
+	}
+
+	data, err := readBlob(fh, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("For uploaded file expected %q got %q", "hello world", data)
+	}
+
+	res = doRequest(t, "PATCH", fileURL, map[string]string{"Upload-Offset": "11"}, "!")
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("For PATCH of completed upload expected status %d got %d", http.StatusUnprocessableEntity,
+			res.StatusCode)
+	}
+
+	res = doRequest(t, "HEAD", ts.URL+"/files/2", nil, "")
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For HEAD of unknown file expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestTusCoreProtocol(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.maxSize = 1024
+	ts := serve(t, fh)
+
+	res := doRequest(t, "OPTIONS", ts.URL+"/files", map[string]string{"Tus-Resumable": ""}, "")
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("For OPTIONS expected status %d got %d", http.StatusNoContent, res.StatusCode)
+	}
+	for header, want := range map[string]string{
+		"Tus-Resumable":          "1.0.0",
+		"Tus-Version":            "1.0.0",
+		"Tus-Extension":          "creation,creation-with-upload,termination,creation-defer-length,checksum,concatenation",
+		"Tus-Checksum-Algorithm": "md5,sha1,sha256",
+		"Tus-Max-Size":           "1024",
+	} {
+		if got := res.Header.Get(header); got != want {
+			t.Errorf("For OPTIONS expected %s %q got %q", header, want, got)
+		}
+	}
+
+	var tests = []struct {
+		name   string
+		method string
+		url    string
+		header map[string]string
+		status int
+	}{
+		{"missing version", "POST", "/files", map[string]string{"Tus-Resumable": "", "Upload-Length": "5"},
+			http.StatusPreconditionFailed},
+		{"unsupported version", "POST", "/files", map[string]string{"Tus-Resumable": "0.2.2", "Upload-Length": "5"},
+			http.StatusPreconditionFailed},
+		{"negative length", "POST", "/files", map[string]string{"Upload-Length": "-1"}, http.StatusBadRequest},
+		{"too large", "POST", "/files", map[string]string{"Upload-Length": "1025"}, http.StatusRequestEntityTooLarge},
+		{"create", "POST", "/files", map[string]string{"Upload-Length": "5"}, http.StatusCreated},
+		{"wrong content type", "PATCH", "/files/1", map[string]string{"Upload-Offset": "0",
+			"Content-Type": "application/octet-stream"}, http.StatusUnsupportedMediaType},
+		{"wrong offset", "PATCH", "/files/1", map[string]string{"Upload-Offset": "3"}, http.StatusConflict},
+	}
+	for _, test := range tests {
+		res := doRequest(t, test.method, ts.URL+test.url, test.header, "")
+		if res.StatusCode != test.status {
+			t.Errorf("For %s expected status %d got %d", test.name, test.status, res.StatusCode)
+		}
+		if res.Header.Get("Tus-Resumable") != "1.0.0" {
+			t.Errorf("For %s expected Tus-Resumable 1.0.0 got %q", test.name, res.Header.Get("Tus-Resumable"))
+		}
+		if test.status == http.StatusPreconditionFailed && res.Header.Get("Tus-Version") != "1.0.0" {
+			t.Errorf("For %s expected Tus-Version 1.0.0 got %q", test.name, res.Header.Get("Tus-Version"))
+		}
+	}
+}
+
+func TestChunkedUpload(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fileURL := ts.URL + "/files/1"
+
+	var chunks = []struct {
+		offset string
+		body   string
+		status int
+		want   string
+	}{
+		{"0", "hello", http.StatusNoContent, "5"},
+		{"5", " world and more", http.StatusRequestEntityTooLarge, ""},
+		{"5", "", http.StatusNoContent, "5"},
+		{"5", " wo", http.StatusNoContent, "8"},
+		{"8", "rld", http.StatusNoContent, "11"},
+	}
+	for _, c := range chunks {
+		res := doRequest(t, "PATCH", fileURL, map[string]string{"Upload-Offset": c.offset}, c.body)
+		if res.StatusCode != c.status || res.Header.Get("Upload-Offset") != c.want {
+			t.Fatalf("For PATCH %q at %s expected status %d offset %q got %d offset %q", c.body, c.offset, c.status,
+				c.want, res.StatusCode, res.Header.Get("Upload-Offset"))
+		}
+	}
+
+	data, err := readBlob(fh, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("For uploaded file expected %q got %q", "hello world", data)
+	}
+}
+
+// droppedReader returns its data and then fails the way a request body does when the client goes away.
+type droppedReader struct {
+	data io.Reader
+}
+
+func (dr droppedReader) Read(p []byte) (int, error) {
+	n, err := dr.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset by peer")
+	}
+	return n, err
+}
+
+func TestInterruptedPatch(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	req := httptest.NewRequest("PATCH", "/files/1", droppedReader{strings.NewReader("hello")})
+	req.Header.Set(headerTusResumable, tusVersion)
+	req.Header.Set(headerContentType, tusOffsetContentType)
+	req.Header.Set(headerUploadOffset, "0")
+	req.ContentLength = 11
+	req = mux.SetURLVars(req, map[string]string{"fileID": "1"})
+	rec := httptest.NewRecorder()
+	fh.filePatchHandler(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("For interrupted PATCH expected status %d got %d", http.StatusInternalServerError, rec.Code)
+	}
+
+	res = doRequest(t, "HEAD", ts.URL+"/files/1", nil, "")
+	if res.Header.Get("Upload-Offset") != "5" {
+		t.Fatalf("After interrupted PATCH expected offset 5 got %q", res.Header.Get("Upload-Offset"))
+	}
+	res = doRequest(t, "PATCH", ts.URL+"/files/1", map[string]string{"Upload-Offset": "5"}, " world")
+	if res.StatusCode != http.StatusNoContent || res.Header.Get("Upload-Offset") != "11" {
+		t.Fatalf("For resumed PATCH expected status %d offset 11 got %d offset %q", http.StatusNoContent,
+			res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+	data, err := readBlob(fh, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("For resumed file expected %q got %q", "hello world", data)
+	}
+}
+
+func TestCreationWithUpload(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11",
+		"Content-Type": tusOffsetContentType}, "hello")
+	if res.StatusCode != http.StatusCreated || res.Header.Get("Upload-Offset") != "5" {
+		t.Fatalf("For POST with upload expected status %d offset 5 got %d offset %q", http.StatusCreated,
+			res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+	res = doRequest(t, "PATCH", ts.URL+"/files/1", map[string]string{"Upload-Offset": "5"}, " world")
+	if res.StatusCode != http.StatusNoContent || res.Header.Get("Upload-Offset") != "11" {
+		t.Fatalf("For PATCH expected status %d offset 11 got %d offset %q", http.StatusNoContent, res.StatusCode,
+			res.Header.Get("Upload-Offset"))
+	}
+
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "2",
+		"Content-Type": tusOffsetContentType}, "hi")
+	if res.StatusCode != http.StatusCreated || res.Header.Get("Upload-Offset") != "2" {
+		t.Fatalf("For complete POST with upload expected status %d offset 2 got %d offset %q", http.StatusCreated,
+			res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+	f, err := fh.store.File("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !*f.uploadComplete {
+		t.Errorf("For complete POST with upload expected upload to be complete")
+	}
+
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "2",
+		"Content-Type": tusOffsetContentType}, "too long")
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("For POST with oversized body expected status %d got %d", http.StatusRequestEntityTooLarge,
+			res.StatusCode)
+	}
+
+	// A PATCH which guessed the next fileID holds its lock, the POST must not write under it.
+	unlock, ok := fh.locks.tryLock("3")
+	if !ok {
+		t.Fatal("Expected to lock upload 3")
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "2",
+		"Content-Type": tusOffsetContentType}, "hi")
+	unlock()
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("For POST with upload of a locked upload expected status %d got %d", http.StatusConflict,
+			res.StatusCode)
+	}
+	f, err = fh.store.File("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 0 {
+		t.Errorf("For POST with upload of a locked upload expected offset 0 got %d", *f.offset)
+	}
+
+	// An upload whose first chunk can't be committed is deleted again.
+	fh.store = failingUpdateStore{fh.store}
+	ts = serve(t, fh)
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "2",
+		"Content-Type": tusOffsetContentType}, "hi")
+	if res.StatusCode != http.StatusInternalServerError || res.Header.Get("Location") != "" {
+		t.Errorf("For POST with upload which fails to commit expected status %d without location got %d at %q",
+			http.StatusInternalServerError, res.StatusCode, res.Header.Get("Location"))
+	}
+	_, err = fh.store.File("4")
+	if err != errFileNotFound {
+		t.Errorf("For POST with upload which fails to commit expected error %v got %v", errFileNotFound, err)
+	}
+}
+
+// failingUpdateStore is a FileStore which can't commit offsets.
+type failingUpdateStore struct {
+	FileStore
+}
+
+func (fs failingUpdateStore) UpdateFileAt(f file, offset int) error {
+	return errors.New("store is read only")
+}
+
+func TestTermination(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fileURL := ts.URL + "/files/1"
+	doRequest(t, "PATCH", fileURL, map[string]string{"Upload-Offset": "0"}, "hello")
+
+	res = doRequest(t, "DELETE", fileURL, nil, "")
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("For DELETE expected status %d got %d", http.StatusNoContent, res.StatusCode)
+	}
+	if _, err := fh.blobs.Open("1"); err != errBlobNotFound {
+		t.Errorf("After DELETE expected file to be removed got %v", err)
+	}
+	res = doRequest(t, "HEAD", fileURL, nil, "")
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For HEAD after DELETE expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+	res = doRequest(t, "DELETE", fileURL, nil, "")
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For second DELETE expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestUploadMetadata(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	// filename world_domination_plan.pdf, is_confidential without a value
+	metadata := "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential"
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11", "Upload-Metadata": metadata},
+		"")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	f, err := fh.store.File("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.uploadMetadata["filename"] != "world_domination_plan.pdf" {
+		t.Errorf("For stored metadata expected filename %q got %q", "world_domination_plan.pdf",
+			f.uploadMetadata["filename"])
+	}
+	res = doRequest(t, "HEAD", ts.URL+"/files/1", nil, "")
+	if res.Header.Get("Upload-Metadata") != metadata {
+		t.Errorf("For HEAD expected Upload-Metadata %q got %q", metadata, res.Header.Get("Upload-Metadata"))
+	}
+
+	for _, bad := range []string{"filename not*base64", "filename a2V5,filename a2V5", "a b c"} {
+		res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11", "Upload-Metadata": bad},
+			"")
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("For metadata %q expected status %d got %d", bad, http.StatusBadRequest, res.StatusCode)
+		}
+	}
+}
+
+func TestDeferredLength(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.maxSize = 100
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Defer-Length": "1"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fileURL := ts.URL + "/files/1"
+	res = doRequest(t, "HEAD", fileURL, nil, "")
+	if res.Header.Get("Upload-Defer-Length") != "1" || res.Header.Get("Upload-Length") != "" {
+		t.Errorf("For HEAD of deferred upload expected Upload-Defer-Length 1 and no length got %q and %q",
+			res.Header.Get("Upload-Defer-Length"), res.Header.Get("Upload-Length"))
+	}
+
+	var steps = []struct {
+		name   string
+		header map[string]string
+		body   string
+		status int
+	}{
+		{"chunk without length", map[string]string{"Upload-Offset": "0"}, "hello", http.StatusNoContent},
+		{"length below offset", map[string]string{"Upload-Offset": "5", "Upload-Length": "4"}, "",
+			http.StatusBadRequest},
+		{"length above max size", map[string]string{"Upload-Offset": "5", "Upload-Length": "101"}, "",
+			http.StatusRequestEntityTooLarge},
+		{"set length", map[string]string{"Upload-Offset": "5", "Upload-Length": "11"}, " wo", http.StatusNoContent},
+		{"last chunk", map[string]string{"Upload-Offset": "8"}, "rld", http.StatusNoContent},
+	}
+	for _, step := range steps {
+		res := doRequest(t, "PATCH", fileURL, step.header, step.body)
+		if res.StatusCode != step.status {
+			t.Fatalf("For %s expected status %d got %d", step.name, step.status, res.StatusCode)
+		}
+	}
+
+	f, err := fh.store.File("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.uploadLength == nil || *f.uploadLength != 11 || !*f.uploadComplete {
+		t.Errorf("For deferred upload expected length 11 and complete got %v and %t", f.uploadLength,
+			*f.uploadComplete)
+	}
+
+	for _, header := range []map[string]string{
+		{"Upload-Defer-Length": "2"},
+		{"Upload-Defer-Length": "1", "Upload-Length": "11"},
+	} {
+		res := doRequest(t, "POST", ts.URL+"/files", header, "")
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("For POST with %v expected status %d got %d", header, http.StatusBadRequest, res.StatusCode)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fileURL := ts.URL + "/files/1"
+
+	var chunks = []struct {
+		name     string
+		checksum string
+		offset   string
+		body     string
+		status   int
+		want     string
+	}{
+		{"unsupported algorithm", "crc32 AAAAAA==", "0", "hello", http.StatusBadRequest, "0"},
+		{"invalid digest", "sha1 not*base64", "0", "hello", http.StatusBadRequest, "0"},
+		{"mismatch", "sha1 " + digest(sha1.New(), "hellp"), "0", "hello", statusChecksumMismatch, "0"},
+		{"sha1", "sha1 " + digest(sha1.New(), "hello"), "0", "hello", http.StatusNoContent, "5"},
+		{"md5", "md5 " + digest(md5.New(), " wo"), "5", " wo", http.StatusNoContent, "8"},
+		{"sha256 mismatch", "sha256 " + digest(sha256.New(), "rlD"), "8", "rld", statusChecksumMismatch, "8"},
+		{"sha256", "sha256 " + digest(sha256.New(), "rld"), "8", "rld", http.StatusNoContent, "11"},
+	}
+	for _, c := range chunks {
+		res := doRequest(t, "PATCH", fileURL, map[string]string{"Upload-Offset": c.offset, "Upload-Checksum": c.checksum},
+			c.body)
+		if res.StatusCode != c.status {
+			t.Errorf("For %s expected status %d got %d", c.name, c.status, res.StatusCode)
+		}
+		res = doRequest(t, "HEAD", fileURL, nil, "")
+		if res.Header.Get("Upload-Offset") != c.want {
+			t.Errorf("After %s expected offset %s got %q", c.name, c.want, res.Header.Get("Upload-Offset"))
+		}
+	}
+
+	data, err := readBlob(fh, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("For uploaded file expected %q got %q", "hello world", data)
+	}
+}
+
+func digest(h hash.Hash, data string) string {
+	h.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func TestConcurrentPatch(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	const size = 1 << 20
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": strconv.Itoa(2 * size)},
+		"")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	// Every PATCH sends the first half of the file in a letter of its own. Exactly one of them may win, the others
+	// have to be turned away and the file must hold the letters of the winner only.
+	const patches = 8
+	statuses := make(chan int, patches)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < patches; i++ {
+		wg.Add(1)
+		go func(letter byte) {
+			defer wg.Done()
+			body := gatedReader{start: start, r: strings.NewReader(strings.Repeat(string(letter), size))}
+			req, err := http.NewRequest("PATCH", ts.URL+"/files/1", body)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			req.Header.Set(headerTusResumable, tusVersion)
+			req.Header.Set(headerContentType, tusOffsetContentType)
+			req.Header.Set(headerUploadOffset, "0")
+			req.ContentLength = size
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+			statuses <- res.StatusCode
+		}(byte('a' + i))
+	}
+	// Give all requests time to reach the handler before any body arrives, so they really overlap.
+	time.Sleep(100 * time.Millisecond)
+	close(start)
+	wg.Wait()
+	close(statuses)
+
+	counts := make(map[int]int)
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[http.StatusNoContent] != 1 || counts[http.StatusConflict] != patches-1 {
+		t.Errorf("For %d concurrent PATCHes expected one %d and the rest %d got %v", patches, http.StatusNoContent,
+			http.StatusConflict, counts)
+	}
+
+	data, err := readBlob(fh, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != size || strings.Count(string(data), string(data[0])) != size {
+		t.Errorf("For concurrently patched file expected %d bytes of one letter got a mix", size)
+	}
+}
+
+// gatedReader holds back the body of a request until start is closed.
+type gatedReader struct {
+	start chan struct{}
+	r     io.Reader
+}
+
+func (gr gatedReader) Read(p []byte) (int, error) {
+	<-gr.start
+	return gr.r.Read(p)
+}
+
+func TestExpiration(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.expiration = time.Hour
+	ts := serve(t, fh)
+
+	res := doRequest(t, "OPTIONS", ts.URL+"/files", nil, "")
+	if !strings.HasSuffix(res.Header.Get("Tus-Extension"), ",expiration") {
+		t.Errorf("For OPTIONS expected expiration extension got %q", res.Header.Get("Tus-Extension"))
+	}
+
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11"}, "")
+	expires, err := http.ParseTime(res.Header.Get("Upload-Expires"))
+	if err != nil {
+		t.Fatalf("For POST expected Upload-Expires got %q", res.Header.Get("Upload-Expires"))
+	}
+	if d := time.Until(expires); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("For POST expected Upload-Expires in an hour got %v", expires)
+	}
+	res = doRequest(t, "PATCH", ts.URL+"/files/1", map[string]string{"Upload-Offset": "0"}, "hello")
+	if res.Header.Get("Upload-Expires") == "" {
+		t.Errorf("For PATCH of incomplete upload expected Upload-Expires")
+	}
+	res = doRequest(t, "HEAD", ts.URL+"/files/1", nil, "")
+	if res.Header.Get("Upload-Expires") == "" {
+		t.Errorf("For HEAD of incomplete upload expected Upload-Expires")
+	}
+
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "2",
+		"Content-Type": tusOffsetContentType}, "hi")
+	if res.Header.Get("Upload-Expires") != "" {
+		t.Errorf("For complete upload expected no Upload-Expires got %q", res.Header.Get("Upload-Expires"))
+	}
+
+	expiring := fh
+	expiring.expiration = time.Nanosecond
+	res = doRequest(t, "HEAD", serve(t, expiring).URL+"/files/1", nil, "")
+	if res.StatusCode != http.StatusGone {
+		t.Errorf("For HEAD of expired upload expected status %d got %d", http.StatusGone, res.StatusCode)
+	}
+
+	deleted, err := fh.collectExpired(time.Now())
+	if err != nil || deleted != 0 {
+		t.Errorf("Before expiration expected no deleted uploads got %d %v", deleted, err)
+	}
+	deleted, err = fh.collectExpired(time.Now().Add(2 * time.Hour))
+	if err != nil || deleted != 1 {
+		t.Errorf("After expiration expected 1 deleted upload got %d %v", deleted, err)
+	}
+	if _, err := fh.blobs.Open("1"); err != errBlobNotFound {
+		t.Errorf("After expiration expected file to be removed got %v", err)
+	}
+	res = doRequest(t, "HEAD", ts.URL+"/files/1", nil, "")
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For HEAD of collected upload expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+	res = doRequest(t, "HEAD", ts.URL+"/files/2", nil, "")
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("For HEAD of complete upload expected status %d got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+// download sends a plain GET, the way a browser would, and returns the response together with its body.
+func download(t *testing.T, url string, header map[string]string) (*http.Response, string) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(body)
+}
+
+func TestDownload(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	// filename world_domination_plan.pdf, filetype application/pdf
+	metadata := "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,filetype YXBwbGljYXRpb24vcGRm"
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "11", "Upload-Metadata": metadata},
+		"")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fileURL := ts.URL + "/files/1"
+
+	res, _ = download(t, fileURL, nil)
+	if res.StatusCode != http.StatusLocked {
+		t.Errorf("For GET of upload in progress expected status %d got %d", http.StatusLocked, res.StatusCode)
+	}
+	doRequest(t, "PATCH", fileURL, map[string]string{"Upload-Offset": "0"}, "hello world")
+
+	res, body := download(t, fileURL, nil)
+	if res.StatusCode != http.StatusOK || body != "hello world" {
+		t.Fatalf("For GET expected status %d and %q got %d and %q", http.StatusOK, "hello world", res.StatusCode, body)
+	}
+	for header, want := range map[string]string{
+		"Content-Type":        "application/pdf",
+		"Content-Disposition": "attachment; filename=world_domination_plan.pdf",
+		"Accept-Ranges":       "bytes",
+	} {
+		if got := res.Header.Get(header); got != want {
+			t.Errorf("For GET expected %s %q got %q", header, want, got)
+		}
+	}
+	etag := res.Header.Get("ETag")
+
+	var tests = []struct {
+		name   string
+		header map[string]string
+		status int
+		body   string
+	}{
+		{"range", map[string]string{"Range": "bytes=6-"}, http.StatusPartialContent, "world"},
+		{"first bytes", map[string]string{"Range": "bytes=0-4"}, http.StatusPartialContent, "hello"},
+		{"if-range match", map[string]string{"Range": "bytes=6-", "If-Range": etag}, http.StatusPartialContent,
+			"world"},
+		{"if-range stale", map[string]string{"Range": "bytes=6-", "If-Range": `"1-0"`}, http.StatusOK,
+			"hello world"},
+		{"unsatisfiable", map[string]string{"Range": "bytes=20-"}, http.StatusRequestedRangeNotSatisfiable, ""},
+	}
+	for _, test := range tests {
+		res, body := download(t, fileURL, test.header)
+		if res.StatusCode != test.status {
+			t.Errorf("For %s expected status %d got %d", test.name, test.status, res.StatusCode)
+		}
+		if test.body != "" && body != test.body {
+			t.Errorf("For %s expected %q got %q", test.name, test.body, body)
+		}
+	}
+
+	res, _ = download(t, ts.URL+"/files/2", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For GET of unknown file expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+
+	// filetype "not a type"
+	doRequest(t, "POST", ts.URL+"/files", map[string]string{
+		"Upload-Length":   "2",
+		"Upload-Metadata": "filetype bm90IGEgdHlwZQ==",
+		"Content-Type":    tusOffsetContentType,
+	}, "hi")
+	res, body = download(t, ts.URL+"/files/2", nil)
+	if body != "hi" || res.Header.Get("Content-Type") != "application/octet-stream" ||
+		res.Header.Get("Content-Disposition") != "attachment" {
+		t.Errorf("For GET without usable metadata expected %q as an octet-stream attachment got %q, %q and %q", "hi",
+			body, res.Header.Get("Content-Type"), res.Header.Get("Content-Disposition"))
+	}
+}
+
+func TestConcatenation(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	for _, length := range []string{"5", "6"} {
+		res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": length,
+			"Upload-Concat": "partial"}, "")
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("For POST of partial upload expected status %d got %d", http.StatusCreated, res.StatusCode)
+		}
+	}
+	doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "1"}, "")
+	doRequest(t, "PATCH", ts.URL+"/files/1", map[string]string{"Upload-Offset": "0"}, "hello")
+
+	var rejected = []struct {
+		name   string
+		concat string
+	}{
+		{"unknown concat", "parallel"},
+		{"no partials", "final;"},
+		{"incomplete partial", "final;/files/1 /files/2"},
+		{"plain upload", "final;/files/1 /files/3"},
+		{"unknown partial", "final;/files/1 /files/9"},
+		{"no upload url", "final;/files/1 /other/2"},
+	}
+	for _, test := range rejected {
+		res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Concat": test.concat}, "")
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("For %s expected status %d got %d", test.name, http.StatusBadRequest, res.StatusCode)
+		}
+	}
+
+	doRequest(t, "PATCH", ts.URL+"/files/2", map[string]string{"Upload-Offset": "0"}, " world")
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{
+		"Upload-Concat": "final;" + ts.URL + "/files/1 /files/2"}, "")
+	if res.StatusCode != http.StatusCreated || res.Header.Get("Location") != ts.URL+"/files/4" {
+		t.Fatalf("For POST of final upload expected status %d at /files/4 got %d at %q", http.StatusCreated,
+			res.StatusCode, res.Header.Get("Location"))
+	}
+
+	res = doRequest(t, "HEAD", ts.URL+"/files/4", nil, "")
+	for header, want := range map[string]string{
+		"Upload-Offset": "11",
+		"Upload-Length": "11",
+		"Upload-Concat": "final;/files/1 /files/2",
+	} {
+		if got := res.Header.Get(header); got != want {
+			t.Errorf("For HEAD of final upload expected %s %q got %q", header, want, got)
+		}
+	}
+	res = doRequest(t, "HEAD", ts.URL+"/files/1", nil, "")
+	if res.Header.Get("Upload-Concat") != "partial" {
+		t.Errorf("For HEAD of partial upload expected Upload-Concat partial got %q", res.Header.Get("Upload-Concat"))
+	}
+	res = doRequest(t, "PATCH", ts.URL+"/files/4", map[string]string{"Upload-Offset": "11"}, "!")
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("For PATCH of final upload expected status %d got %d", http.StatusForbidden, res.StatusCode)
+	}
+	data, err := readBlob(fh, "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("For final upload expected %q got %q", "hello world", data)
+	}
+}
+
+func TestConcatenationFailure(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+
+	for _, body := range []string{"hello", " world"} {
+		res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": strconv.Itoa(len(body)),
+			"Upload-Concat": "partial", "Content-Type": tusOffsetContentType}, body)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("For POST of partial upload expected status %d got %d", http.StatusCreated, res.StatusCode)
+		}
+	}
+
+	unlock, ok := fh.locks.tryLock("2")
+	if !ok {
+		t.Fatal("Expected to lock partial upload 2")
+	}
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Concat": "final;/files/1 /files/2"}, "")
+	if res.StatusCode != http.StatusLocked {
+		t.Errorf("For final upload of a locked partial expected status %d got %d", http.StatusLocked, res.StatusCode)
+	}
+	unlock()
+
+	// The final upload whose partial lost its blob is deleted again instead of lingering incomplete.
+	err := fh.blobs.Delete("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Concat": "final;/files/1 /files/2"}, "")
+	if res.StatusCode != http.StatusInternalServerError || res.Header.Get("Location") != "" {
+		t.Errorf("For final upload of a broken partial expected status %d without location got %d at %q",
+			http.StatusInternalServerError, res.StatusCode, res.Header.Get("Location"))
+	}
+	_, err = fh.store.File("3")
+	if err != errFileNotFound {
+		t.Errorf("For failed final upload expected error %v got %v", errFileNotFound, err)
+	}
+	if _, err := readBlob(fh, "3"); err == nil {
+		t.Errorf("For failed final upload expected its blob to be deleted")
+	}
+}
+
+func TestFileLocation(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "5"}, "")
+	if res.Header.Get("Location") != ts.URL+"/files/1" {
+		t.Errorf("Expected Location %s/files/1 from the request got %q", ts.URL, res.Header.Get("Location"))
+	}
+
+	fh.baseURL = "https://uploads.example.com/tus"
+	ts = serve(t, fh)
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "5"}, "")
+	if res.Header.Get("Location") != "https://uploads.example.com/tus/files/2" {
+		t.Errorf("Expected Location below the base URL got %q", res.Header.Get("Location"))
+	}
+}
+
+func TestTusClient(t *testing.T) {
+	fh := newTestHandler(t)
+	ts := serve(t, fh)
+	data := strings.Repeat("resumable ", 1000)
+
+	c := tusclient.Client{ChunkSize: 3000}
+	uploadURL, err := c.Upload(ts.URL+"/files", strings.NewReader(data), int64(len(data)), "",
+		map[string]string{"filename": "resumable.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploadURL != ts.URL+"/files/1" {
+		t.Errorf("Expected upload at %s/files/1 got %s", ts.URL, uploadURL)
+	}
+	res, body := download(t, uploadURL, nil)
+	if res.StatusCode != http.StatusOK || body != data {
+		t.Errorf("Expected to download the uploaded data got status %d and %d bytes", res.StatusCode, len(body))
+	}
+
+	// An empty file is complete once it is created, without a PATCH.
+	uploadURL, err = c.Upload(ts.URL+"/files", strings.NewReader(""), 0, "", map[string]string{"filename": "empty.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, body = download(t, uploadURL, nil)
+	if res.StatusCode != http.StatusOK || body != "" {
+		t.Errorf("Expected to download the empty file got status %d and %d bytes", res.StatusCode, len(body))
+	}
+}
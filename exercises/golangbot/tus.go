@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Headers and values of the tus 1.0.0 core protocol, https://tus.io/protocols/resumable-upload.html
+const (
+	tusVersion              = "1.0.0"
+	tusOffsetContentType    = "application/offset+octet-stream"
+	headerTusResumable      = "Tus-Resumable"
+	headerTusVersion        = "Tus-Version"
+	headerTusExtension      = "Tus-Extension"
+	headerTusMaxSize        = "Tus-Max-Size"
+	headerUploadOffset      = "Upload-Offset"
+	headerUploadLength      = "Upload-Length"
+	headerUploadDeferLength = "Upload-Defer-Length"
+	headerUploadMetadata    = "Upload-Metadata"
+	headerUploadChecksum    = "Upload-Checksum"
+	headerUploadExpires     = "Upload-Expires"
+	headerUploadConcat      = "Upload-Concat"
+	headerTusChecksumAlgo   = "Tus-Checksum-Algorithm"
+	headerContentType       = "Content-Type"
+	headerCacheControl      = "Cache-Control"
+	headerLocation          = "Location"
+	cacheControlNoStore     = "no-store"
+)
+
+// statusChecksumMismatch is the status the checksum extension defines for a chunk which doesn't match its checksum.
+const statusChecksumMismatch = 460
+
+// tusExtensions lists the protocol extensions the uploader supports, in the order they are advertised.
+var tusExtensions = []string{"creation", "creation-with-upload", "termination", "creation-defer-length", "checksum",
+	"concatenation"}
+
+// checksumAlgorithms lists the hash algorithms Upload-Checksum may use, in the order they are advertised.
+var checksumAlgorithms = []string{"md5", "sha1", "sha256"}
+
+var checksumHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+/*
+tusResumable wraps every handler of the uploader. It adds the Tus-Resumable header to every response and rejects
+requests which don't speak the protocol version the uploader implements with 412 Precondition Failed. OPTIONS
+requests are exempt because clients use them to find out which versions are supported in the first place, GET
+requests because downloads are not part of tus and come from plain HTTP clients.
+*/
+func tusResumable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerTusResumable, tusVersion)
+		if r.Method != http.MethodOptions && r.Method != http.MethodGet && r.Header.Get(headerTusResumable) != tusVersion {
+			log.Printf("Unsupported tus version %q\n", r.Header.Get(headerTusResumable))
+			w.Header().Set(headerTusVersion, tusVersion)
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// optionsHandler answers the discovery request clients send to learn the capabilities of the uploader.
+func (fh fileHandler) optionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(headerTusVersion, tusVersion)
+	extensions := append([]string{}, tusExtensions...)
+	if fh.expiration > 0 {
+		extensions = append(extensions, "expiration")
+	}
+	w.Header().Set(headerTusExtension, strings.Join(extensions, ","))
+	w.Header().Set(headerTusChecksumAlgo, strings.Join(checksumAlgorithms, ","))
+	if fh.maxSize > 0 {
+		w.Header().Set(headerTusMaxSize, strconv.Itoa(fh.maxSize))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+parseMetadata decodes an Upload-Metadata header: comma separated pairs of a key and its base64 encoded value, split by
+a space. The value may be left out, keys have to be unique.
+*/
+func parseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		kv := strings.Fields(pair)
+		if len(kv) == 0 || len(kv) > 2 {
+			return nil, fmt.Errorf("invalid metadata pair %q", pair)
+		}
+		if _, ok := metadata[kv[0]]; ok {
+			return nil, fmt.Errorf("duplicate metadata key %q", kv[0])
+		}
+		value := ""
+		if len(kv) == 2 {
+			v, err := base64.StdEncoding.DecodeString(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid metadata value for key %q: %v", kv[0], err)
+			}
+			value = string(v)
+		}
+		metadata[kv[0]] = value
+	}
+	return metadata, nil
+}
+
+// encodeMetadata is the reverse of parseMetadata. Keys are sorted so the header is the same on every HEAD.
+func encodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if metadata[k] == "" {
+			pairs = append(pairs, k)
+			continue
+		}
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(metadata[k])))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// checksum is the expected digest of a chunk together with the hash the chunk is fed through while it is written.
+type checksum struct {
+	hash   hash.Hash
+	digest []byte
+}
+
+func (c *checksum) matches() bool {
+	return bytes.Equal(c.hash.Sum(nil), c.digest)
+}
+
+/*
+parseChecksum decodes an Upload-Checksum header, the name of the algorithm and the base64 encoded digest split by a
+space. It returns nil if the header is empty.
+*/
+func parseChecksum(header string) (*checksum, error) {
+	if header == "" {
+		return nil, nil
+	}
+	parts := strings.Fields(header)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid checksum %q", header)
+	}
+	newHash, ok := checksumHashes[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", parts[0])
+	}
+	digest, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum digest: %v", err)
+	}
+	return &checksum{hash: newHash(), digest: digest}, nil
+}
@@ -0,0 +1,420 @@
+/*
+Package tusclient uploads files to a tus 1.0.0 server such as the resumable file uploader of this directory. It creates
+an upload with POST, sends the bytes in PATCH chunks and, when a chunk fails halfway, asks the server with HEAD how
+many bytes it kept and carries on from there. Uploads in progress can be remembered in a Store, so a later run resumes
+them instead of starting over.
+*/
+package tusclient
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tusVersion           = "1.0.0"
+	tusOffsetContentType = "application/offset+octet-stream"
+
+	headerTusResumable   = "Tus-Resumable"
+	headerUploadOffset   = "Upload-Offset"
+	headerUploadLength   = "Upload-Length"
+	headerUploadMetadata = "Upload-Metadata"
+	headerContentType    = "Content-Type"
+	headerLocation       = "Location"
+)
+
+// Defaults used for the zero values of the fields of Client.
+const (
+	DefaultChunkSize  = 1 << 20
+	DefaultMaxRetries = 5
+	DefaultBackoff    = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+/*
+StatusError is returned when the server answers a request with an unexpected status. Body holds the beginning of the
+response body, the uploader explains most rejections there.
+*/
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+/*
+Client uploads files to a tus server. The zero value is ready to use, zero fields fall back to http.DefaultClient and
+the Default constants.
+
+A failed request is retried up to MaxRetries times, waiting Backoff before the first retry and twice as long before
+every further one, but never longer than MaxBackoff. The counter starts over whenever a chunk went through and a
+negative MaxRetries turns retries off. Without a Store every Upload creates a new upload on the server.
+*/
+type Client struct {
+	HTTPClient *http.Client
+	ChunkSize  int64
+	MaxRetries int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Store      Store
+	// Progress, if set, is called with the offset of the upload after every chunk.
+	Progress func(offset, length int64)
+
+	// sleep waits between retries, tests replace it to not wait for real.
+	sleep func(time.Duration)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c *Client) chunkSize() int64 {
+	if c.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return c.ChunkSize
+}
+
+func (c *Client) maxRetries() int {
+	if c.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	if c.MaxRetries < 0 {
+		return 0
+	}
+	return c.MaxRetries
+}
+
+// backoff returns how long to wait before the retry-th retry.
+func (c *Client) backoff(retry int) time.Duration {
+	d, max := c.Backoff, c.MaxBackoff
+	if d <= 0 {
+		d = DefaultBackoff
+	}
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (c *Client) wait(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+/*
+retryable tells whether a request which failed with err may succeed when it is sent again: the connection broke, the
+server had a problem or another request was working on the upload at the same time.
+*/
+func retryable(err error) bool {
+	se, ok := err.(*StatusError)
+	if !ok {
+		return true
+	}
+	switch se.StatusCode {
+	case http.StatusConflict, http.StatusTooManyRequests:
+		return true
+	}
+	return se.StatusCode >= 500
+}
+
+/*
+UploadFile uploads the file at path to the tus server whose creation URL is createURL, for example
+http://localhost:8080/files, and returns the URL of the upload. The name and the type of the file are sent as the
+filename and filetype metadata. The file is known to the Store by its absolute path, size and modification time, so it
+is uploaded anew once it changed.
+*/
+func (c *Client) UploadFile(createURL, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	metadata := map[string]string{"filename": filepath.Base(abs)}
+	if t := mime.TypeByExtension(filepath.Ext(abs)); t != "" {
+		metadata["filetype"] = t
+	}
+	fingerprint := fmt.Sprintf("%s %s %d %d", createURL, abs, fi.Size(), fi.ModTime().UnixNano())
+	return c.Upload(createURL, f, fi.Size(), fingerprint, metadata)
+}
+
+/*
+Upload uploads length bytes read from r and returns the URL of the upload. If the Store knows an upload for
+fingerprint which the server still has, it is resumed from the offset the server reports, otherwise a new upload is
+created with metadata. The upload is forgotten by the Store once it is complete.
+*/
+func (c *Client) Upload(createURL string, r io.ReaderAt, length int64, fingerprint string, metadata map[string]string) (string, error) {
+	uploadURL, offset, err := c.resume(fingerprint, length)
+	if err != nil {
+		return "", err
+	}
+	if uploadURL == "" {
+		uploadURL, err = c.create(createURL, length, metadata)
+		if err != nil {
+			return "", err
+		}
+		offset = 0
+		if c.Store != nil && fingerprint != "" {
+			err = c.Store.Set(fingerprint, uploadURL)
+			if err != nil {
+				return uploadURL, err
+			}
+		}
+	}
+
+	retries := 0
+	for offset < length {
+		n := length - offset
+		if n > c.chunkSize() {
+			n = c.chunkSize()
+		}
+		next, err := c.patch(uploadURL, io.NewSectionReader(r, offset, n), offset, n)
+		if err == nil {
+			offset = next
+			retries = 0
+			if c.Progress != nil {
+				c.Progress(offset, length)
+			}
+			continue
+		}
+		// The chunk may have been cut off anywhere, so whatever the server kept is asked for with HEAD before the
+		// next PATCH. HEAD may fail for the same reasons and is retried with the same counter.
+		for {
+			if !retryable(err) || retries >= c.maxRetries() {
+				return uploadURL, err
+			}
+			retries++
+			c.wait(c.backoff(retries))
+			var l int64
+			offset, l, err = c.head(uploadURL)
+			if err == nil && l != length {
+				return uploadURL, fmt.Errorf("upload %s has length %d instead of %d", uploadURL, l, length)
+			}
+			if err == nil {
+				break
+			}
+		}
+	}
+	if c.Store != nil && fingerprint != "" {
+		err = c.Store.Delete(fingerprint)
+		if err != nil {
+			return uploadURL, err
+		}
+	}
+	return uploadURL, nil
+}
+
+/*
+resume looks up the upload the Store remembers for fingerprint and returns its URL and offset. It returns an empty URL
+if there is nothing to resume: no upload is remembered, the server deleted it in the meantime or it has another length.
+*/
+func (c *Client) resume(fingerprint string, length int64) (string, int64, error) {
+	if c.Store == nil || fingerprint == "" {
+		return "", 0, nil
+	}
+	uploadURL, ok, err := c.Store.Get(fingerprint)
+	if err != nil || !ok {
+		return "", 0, err
+	}
+	retries := 0
+	for {
+		offset, l, err := c.head(uploadURL)
+		if err == nil && l == length {
+			return uploadURL, offset, nil
+		}
+		se, ok := err.(*StatusError)
+		if err == nil || ok && (se.StatusCode == http.StatusNotFound || se.StatusCode == http.StatusGone) {
+			return "", 0, c.Store.Delete(fingerprint)
+		}
+		if !retryable(err) || retries >= c.maxRetries() {
+			return "", 0, err
+		}
+		retries++
+		c.wait(c.backoff(retries))
+	}
+}
+
+/*
+Offset returns how many bytes of the upload at uploadURL the server has, retrying the HEAD request like the chunks of
+an upload.
+*/
+func (c *Client) Offset(uploadURL string) (int64, error) {
+	retries := 0
+	for {
+		offset, _, err := c.head(uploadURL)
+		if err == nil || !retryable(err) || retries >= c.maxRetries() {
+			return offset, err
+		}
+		retries++
+		c.wait(c.backoff(retries))
+	}
+}
+
+// create sends the POST which creates an upload of length bytes and returns the absolute URL of the upload.
+func (c *Client) create(createURL string, length int64, metadata map[string]string) (string, error) {
+	retries := 0
+	for {
+		req, err := http.NewRequest("POST", createURL, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set(headerTusResumable, tusVersion)
+		req.Header.Set(headerUploadLength, strconv.FormatInt(length, 10))
+		if len(metadata) > 0 {
+			req.Header.Set(headerUploadMetadata, encodeMetadata(metadata))
+		}
+		res, err := c.do(req, http.StatusCreated)
+		if err == nil {
+			return resolveLocation(req.URL, res.Header.Get(headerLocation))
+		}
+		if !retryable(err) || retries >= c.maxRetries() {
+			return "", err
+		}
+		retries++
+		c.wait(c.backoff(retries))
+	}
+}
+
+// head returns the offset and the length of the upload at uploadURL.
+func (c *Client) head(uploadURL string) (int64, int64, error) {
+	req, err := http.NewRequest("HEAD", uploadURL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set(headerTusResumable, tusVersion)
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := strconv.ParseInt(res.Header.Get(headerUploadOffset), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("improper %s from HEAD %s: %v", headerUploadOffset, uploadURL, err)
+	}
+	length, err := strconv.ParseInt(res.Header.Get(headerUploadLength), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("improper %s from HEAD %s: %v", headerUploadLength, uploadURL, err)
+	}
+	return offset, length, nil
+}
+
+// patch sends n bytes read from body as the chunk at offset and returns the offset the server answers with.
+func (c *Client) patch(uploadURL string, body io.Reader, offset, n int64) (int64, error) {
+	req, err := http.NewRequest("PATCH", uploadURL, body)
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = n
+	req.Header.Set(headerTusResumable, tusVersion)
+	req.Header.Set(headerContentType, tusOffsetContentType)
+	req.Header.Set(headerUploadOffset, strconv.FormatInt(offset, 10))
+	res, err := c.do(req, http.StatusNoContent)
+	if err != nil {
+		return 0, err
+	}
+	next, err := strconv.ParseInt(res.Header.Get(headerUploadOffset), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("improper %s from PATCH %s: %v", headerUploadOffset, uploadURL, err)
+	}
+	if next <= offset {
+		return 0, fmt.Errorf("PATCH %s did not move the offset %d", uploadURL, offset)
+	}
+	return next, nil
+}
+
+// do sends req and returns a StatusError unless the server answers with status. The body of the response is closed.
+func (c *Client) do(req *http.Request, status int) (*http.Response, error) {
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != status {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, &StatusError{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: res.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	return res, nil
+}
+
+/*
+resolveLocation turns the Location of a created upload into an absolute URL. Relative locations are resolved against
+the creation URL. A location without a scheme, like localhost:8080/files/1, gets the scheme of the creation URL.
+*/
+func resolveLocation(createURL *url.URL, location string) (string, error) {
+	if location == "" {
+		return "", fmt.Errorf("POST %s: no %s in the response", createURL, headerLocation)
+	}
+	if !strings.Contains(location, "://") && !strings.HasPrefix(location, "/") {
+		if host, _, err := net.SplitHostPort(strings.SplitN(location, "/", 2)[0]); err == nil && host != "" {
+			location = createURL.Scheme + "://" + location
+		}
+	}
+	u, err := createURL.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("POST %s: improper %s %q: %v", createURL, headerLocation, location, err)
+	}
+	return u.String(), nil
+}
+
+// encodeMetadata encodes an Upload-Metadata header, comma separated keys and base64 encoded values.
+func encodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if metadata[k] == "" {
+			pairs = append(pairs, k)
+			continue
+		}
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(metadata[k])))
+	}
+	return strings.Join(pairs, ",")
+}
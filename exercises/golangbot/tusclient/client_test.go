@@ -0,0 +1,378 @@
+package tusclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+fakeTus is a tus server just good enough for the client. Like the uploader it answers POST with a Location without a
+scheme and keeps whatever part of a PATCH body arrived. dropPatches PATCHes are cut off after half their body and
+failHeads HEADs are answered with 503, before the server behaves again.
+*/
+type fakeTus struct {
+	mu          sync.Mutex
+	uploads     map[string]*fakeUpload
+	maxSize     int64
+	dropPatches int
+	failHeads   int
+	posts       int
+	patches     int
+	metadata    string
+}
+
+type fakeUpload struct {
+	length int64
+	data   []byte
+}
+
+func newFakeTus(t *testing.T) (*fakeTus, *httptest.Server) {
+	ft := &fakeTus{uploads: make(map[string]*fakeUpload)}
+	ts := httptest.NewServer(ft)
+	t.Cleanup(ts.Close)
+	return ft, ts
+}
+
+func (ft *fakeTus) upload(fileID string) *fakeUpload {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	return ft.uploads[fileID]
+}
+
+func (ft *fakeTus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if r.Header.Get(headerTusResumable) != tusVersion {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if r.Method == "POST" && r.URL.Path == "/files" {
+		ft.posts++
+		length, err := strconv.ParseInt(r.Header.Get(headerUploadLength), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if ft.maxSize > 0 && length > ft.maxSize {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(w, "Upload length %d exceeds the maximum size %d", length, ft.maxSize)
+			return
+		}
+		ft.metadata = r.Header.Get(headerUploadMetadata)
+		fileID := strconv.Itoa(len(ft.uploads) + 1)
+		ft.uploads[fileID] = &fakeUpload{length: length}
+		w.Header().Set(headerLocation, r.Host+"/files/"+fileID)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	u, ok := ft.uploads[strings.TrimPrefix(r.URL.Path, "/files/")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case "HEAD":
+		if ft.failHeads > 0 {
+			ft.failHeads--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(headerUploadOffset, strconv.Itoa(len(u.data)))
+		w.Header().Set(headerUploadLength, strconv.FormatInt(u.length, 10))
+		w.WriteHeader(http.StatusOK)
+	case "PATCH":
+		ft.patches++
+		if r.Header.Get(headerContentType) != tusOffsetContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if r.Header.Get(headerUploadOffset) != strconv.Itoa(len(u.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if ft.dropPatches > 0 {
+			ft.dropPatches--
+			half, _ := ioutil.ReadAll(io.LimitReader(r.Body, r.ContentLength/2))
+			u.data = append(u.data, half...)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		u.data = append(u.data, body...)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(headerUploadOffset, strconv.Itoa(len(u.data)))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+// newTestClient returns a client which records the waits between retries instead of sleeping.
+func newTestClient(waits *[]time.Duration) *Client {
+	return &Client{
+		ChunkSize:  3000,
+		Backoff:    time.Second,
+		MaxBackoff: 3 * time.Second,
+		sleep: func(d time.Duration) {
+			*waits = append(*waits, d)
+		},
+	}
+}
+
+func TestUpload(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	var waits []time.Duration
+	c := newTestClient(&waits)
+	var progress []int64
+	c.Progress = func(offset, length int64) {
+		progress = append(progress, offset)
+	}
+	data := testData(10000)
+
+	uploadURL, err := c.Upload(ts.URL+"/files", bytes.NewReader(data), int64(len(data)), "", map[string]string{"filename": "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploadURL != ts.URL+"/files/1" {
+		t.Errorf("upload URL %s, expected %s", uploadURL, ts.URL+"/files/1")
+	}
+	if !bytes.Equal(ft.upload("1").data, data) {
+		t.Error("uploaded bytes differ from the data")
+	}
+	if ft.patches != 4 {
+		t.Errorf("%d PATCHes for 10000 bytes in chunks of 3000, expected 4", ft.patches)
+	}
+	if fmt.Sprint(progress) != "[3000 6000 9000 10000]" {
+		t.Errorf("progress %v", progress)
+	}
+	expected := "filename " + base64.StdEncoding.EncodeToString([]byte("a.txt"))
+	if ft.metadata != expected {
+		t.Errorf("Upload-Metadata %q, expected %q", ft.metadata, expected)
+	}
+	if len(waits) != 0 {
+		t.Errorf("waited %v without failures", waits)
+	}
+}
+
+func TestUploadEmpty(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	var waits []time.Duration
+	c := newTestClient(&waits)
+	c.Store = &memoryStore{uploads: map[string]string{}}
+
+	uploadURL, err := c.Upload(ts.URL+"/files", bytes.NewReader(nil), 0, "empty", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploadURL != ts.URL+"/files/1" || ft.upload("1").length != 0 {
+		t.Errorf("upload URL %s, expected an upload of length 0 at %s", uploadURL, ts.URL+"/files/1")
+	}
+	if ft.posts != 1 || ft.patches != 0 {
+		t.Errorf("%d POSTs and %d PATCHes, expected the POST alone", ft.posts, ft.patches)
+	}
+	if _, ok, _ := c.Store.Get("empty"); ok {
+		t.Error("store still remembers the complete upload")
+	}
+}
+
+func TestUploadRecoversDroppedConnection(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	ft.dropPatches = 2
+	ft.failHeads = 1
+	var waits []time.Duration
+	c := newTestClient(&waits)
+	data := testData(10000)
+
+	_, err := c.Upload(ts.URL+"/files", bytes.NewReader(data), int64(len(data)), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ft.upload("1").data, data) {
+		t.Error("uploaded bytes differ from the data")
+	}
+	// The first PATCH is dropped, the HEAD after it fails, the PATCH after the next HEAD is dropped again.
+	if fmt.Sprint(waits) != "[1s 2s 3s]" {
+		t.Errorf("waits %v, expected [1s 2s 3s]", waits)
+	}
+}
+
+func TestUploadGivesUp(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	ft.dropPatches = 100
+	var waits []time.Duration
+	c := newTestClient(&waits)
+	c.MaxRetries = 2
+	data := testData(10000)
+
+	_, err := c.Upload(ts.URL+"/files", bytes.NewReader(data), int64(len(data)), "", nil)
+	if err == nil {
+		t.Fatal("upload succeeded although every PATCH was dropped")
+	}
+	if len(waits) != 2 {
+		t.Errorf("retried %d times, expected 2", len(waits))
+	}
+}
+
+func TestUploadRejected(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	ft.maxSize = 100
+	var waits []time.Duration
+	c := newTestClient(&waits)
+
+	_, err := c.Upload(ts.URL+"/files", bytes.NewReader(testData(1000)), 1000, "", nil)
+	se, ok := err.(*StatusError)
+	if !ok || se.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("error %v, expected 413", err)
+	}
+	if !strings.Contains(se.Error(), "exceeds the maximum size 100") {
+		t.Errorf("error %q does not contain the explanation of the server", se)
+	}
+	if ft.posts != 1 || len(waits) != 0 {
+		t.Errorf("%d POSTs and waits %v, a rejection should not be retried", ft.posts, waits)
+	}
+}
+
+func TestUploadFileResume(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	dir, err := ioutil.TempDir("", "tusclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	path := filepath.Join(dir, "data.txt")
+	data := testData(10000)
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "state.json")
+
+	ft.dropPatches = 2
+	var waits []time.Duration
+	c := newTestClient(&waits)
+	c.MaxRetries = -1
+	c.Store = &FileStore{Path: statePath}
+	_, err = c.UploadFile(ts.URL+"/files", path)
+	if err == nil {
+		t.Fatal("upload succeeded although retries are off and the PATCH was dropped")
+	}
+	if n := len(ft.upload("1").data); n != 1500 {
+		t.Fatalf("server kept %d bytes, expected 1500", n)
+	}
+
+	// A new client, as if the command was run again, resumes the upload it finds in the file.
+	c = newTestClient(&waits)
+	c.Store = &FileStore{Path: statePath}
+	uploadURL, err := c.UploadFile(ts.URL+"/files", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploadURL != ts.URL+"/files/1" || ft.posts != 1 {
+		t.Errorf("upload %s after %d POSTs, expected the first upload to be resumed", uploadURL, ft.posts)
+	}
+	if !bytes.Equal(ft.upload("1").data, data) {
+		t.Error("uploaded bytes differ from the file")
+	}
+	expected := "filename " + base64.StdEncoding.EncodeToString([]byte("data.txt")) +
+		",filetype " + base64.StdEncoding.EncodeToString([]byte("text/plain; charset=utf-8"))
+	if ft.metadata != expected {
+		t.Errorf("Upload-Metadata %q, expected %q", ft.metadata, expected)
+	}
+	state, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(state) != "{}" {
+		t.Errorf("state %s left behind after the upload completed", state)
+	}
+}
+
+func TestUploadRestartsLostUpload(t *testing.T) {
+	ft, ts := newFakeTus(t)
+	var waits []time.Duration
+	c := newTestClient(&waits)
+	c.Store = &memoryStore{uploads: map[string]string{"data": ts.URL + "/files/99"}}
+	data := testData(100)
+
+	uploadURL, err := c.Upload(ts.URL+"/files", bytes.NewReader(data), int64(len(data)), "data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploadURL != ts.URL+"/files/1" || ft.posts != 1 {
+		t.Errorf("upload %s after %d POSTs, expected a new upload", uploadURL, ft.posts)
+	}
+	if !bytes.Equal(ft.upload("1").data, data) {
+		t.Error("uploaded bytes differ from the data")
+	}
+}
+
+type memoryStore struct {
+	uploads map[string]string
+}
+
+func (ms *memoryStore) Get(fingerprint string) (string, bool, error) {
+	uploadURL, ok := ms.uploads[fingerprint]
+	return uploadURL, ok, nil
+}
+
+func (ms *memoryStore) Set(fingerprint, uploadURL string) error {
+	ms.uploads[fingerprint] = uploadURL
+	return nil
+}
+
+func (ms *memoryStore) Delete(fingerprint string) error {
+	delete(ms.uploads, fingerprint)
+	return nil
+}
+
+func TestResolveLocation(t *testing.T) {
+	createURL, _ := url.Parse("https://example.com:8443/files")
+	for location, expected := range map[string]string{
+		"https://other.com/files/1": "https://other.com/files/1",
+		"/files/2":                  "https://example.com:8443/files/2",
+		"files/3":                   "https://example.com:8443/files/3",
+		"localhost:8080/files/4":    "https://localhost:8080/files/4",
+	} {
+		u, err := resolveLocation(createURL, location)
+		if err != nil {
+			t.Errorf("%s: %v", location, err)
+			continue
+		}
+		if u != expected {
+			t.Errorf("%s resolved to %s, expected %s", location, u, expected)
+		}
+	}
+	_, err := resolveLocation(createURL, "")
+	if err == nil {
+		t.Error("resolved an empty Location")
+	}
+}
@@ -0,0 +1,108 @@
+package tusclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+/*
+Store remembers the URLs of uploads in progress by the fingerprint of what is uploaded, so an upload which was
+interrupted can be resumed by a later Upload. Get returns false if nothing is remembered for fingerprint.
+*/
+type Store interface {
+	Get(fingerprint string) (string, bool, error)
+	Set(fingerprint, uploadURL string) error
+	Delete(fingerprint string) error
+}
+
+/*
+FileStore is a Store which keeps the URLs in a JSON file at Path, so they survive the process. The file is replaced as
+a whole on every change and created once the first URL is set.
+*/
+type FileStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (fs *FileStore) Get(fingerprint string) (string, bool, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	uploads, err := fs.load()
+	if err != nil {
+		return "", false, err
+	}
+	uploadURL, ok := uploads[fingerprint]
+	return uploadURL, ok, nil
+}
+
+func (fs *FileStore) Set(fingerprint, uploadURL string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	uploads, err := fs.load()
+	if err != nil {
+		return err
+	}
+	uploads[fingerprint] = uploadURL
+	return fs.save(uploads)
+}
+
+func (fs *FileStore) Delete(fingerprint string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	uploads, err := fs.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := uploads[fingerprint]; !ok {
+		return nil
+	}
+	delete(uploads, fingerprint)
+	return fs.save(uploads)
+}
+
+func (fs *FileStore) load() (map[string]string, error) {
+	uploads := make(map[string]string)
+	data, err := ioutil.ReadFile(fs.Path)
+	if os.IsNotExist(err) {
+		return uploads, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &uploads)
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// save writes uploads to a temporary file next to Path first, so a crash never leaves half a file behind.
+func (fs *FileStore) save(uploads map[string]string) error {
+	data, err := json.MarshalIndent(uploads, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(fs.Path), filepath.Base(fs.Path))
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	cerr := tmp.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), fs.Path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
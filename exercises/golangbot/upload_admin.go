@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Page sizes of the admin listing, chosen with ?limit.
+const (
+	adminDefaultLimit = 50
+	adminMaxLimit     = 1000
+)
+
+// adminDeleteWait is how long a forced delete waits for a request working on the upload.
+const adminDeleteWait = 10 * time.Second
+
+// adminUpload is an upload as the admin listing shows it.
+type adminUpload struct {
+	ID         string            `json:"id"`
+	Offset     int               `json:"offset"`
+	Length     *int              `json:"length"`   // null while the length is deferred
+	Progress   *float64          `json:"progress"` // percent of the length received, null while it is deferred
+	Complete   bool              `json:"complete"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Concat     string            `json:"concat,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ModifiedAt time.Time         `json:"modified_at"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
+}
+
+type adminUploadList struct {
+	Uploads []adminUpload `json:"uploads"`
+	// Next is the value of ?after for the next page, empty on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+func (fh fileHandler) adminUpload(f file) adminUpload {
+	au := adminUpload{
+		ID:         strconv.Itoa(f.fileID),
+		Offset:     *f.offset,
+		Length:     f.uploadLength,
+		Complete:   *f.uploadComplete,
+		Metadata:   f.uploadMetadata,
+		Concat:     f.uploadConcat,
+		Owner:      f.owner,
+		CreatedAt:  f.createdAt,
+		ModifiedAt: f.modifiedAt,
+	}
+	if f.uploadLength != nil {
+		progress := 100.0
+		if *f.uploadLength > 0 {
+			progress = float64(*f.offset) * 100 / float64(*f.uploadLength)
+		}
+		au.Progress = &progress
+	}
+	if expires := fh.expires(f); !expires.IsZero() {
+		au.ExpiresAt = &expires
+	}
+	return au
+}
+
+/*
+parseFileFilter reads the filter of the admin listing from the query: status complete or incomplete, created_before
+as an RFC 3339 time, filename, which has to be part of the filename metadata, and the page, after and limit.
+*/
+func parseFileFilter(r *http.Request) (fileFilter, string) {
+	q := r.URL.Query()
+	filter := fileFilter{limit: adminDefaultLimit}
+	switch q.Get("status") {
+	case "":
+	case "complete", "incomplete":
+		complete := q.Get("status") == "complete"
+		filter.complete = &complete
+	default:
+		return filter, "status must be complete or incomplete"
+	}
+	if v := q.Get("created_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, "created_before must be an RFC 3339 time"
+		}
+		filter.createdBefore = t
+	}
+	filter.filename = q.Get("filename")
+	if v := q.Get("after"); v != "" {
+		after, err := strconv.Atoi(v)
+		if err != nil || after < 0 {
+			return filter, "after must be a fileID"
+		}
+		filter.after = after
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > adminMaxLimit {
+			return filter, "limit must be between 1 and " + strconv.Itoa(adminMaxLimit)
+		}
+		filter.limit = limit
+	}
+	return filter, ""
+}
+
+/*
+adminListHandler lists the uploads page by page. One upload more than the page holds is fetched, so the response only
+points to a next page if there is one.
+*/
+func (fh fileHandler) adminListHandler(w http.ResponseWriter, r *http.Request) {
+	filter, e := parseFileFilter(r)
+	if e != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(e))
+		return
+	}
+	limit := filter.limit
+	filter.limit++
+	files, err := fh.store.ListFiles(filter)
+	if err != nil {
+		log.Println("Error while listing files", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	list := adminUploadList{Uploads: []adminUpload{}}
+	if len(files) > limit {
+		files = files[:limit]
+		list.Next = strconv.Itoa(files[limit-1].fileID)
+	}
+	for _, f := range files {
+		list.Uploads = append(list.Uploads, fh.adminUpload(f))
+	}
+	w.Header().Set(headerContentType, "application/json")
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
+	json.NewEncoder(w).Encode(list)
+}
+
+/*
+adminDeleteHandler forces an upload to be deleted, whatever state it is in and whoever created it. Unlike termination
+it doesn't give up if a PATCH is in flight but waits for it to finish, deleting the blob under it would leave its
+bytes behind. Only a PATCH running longer than adminDeleteWait gets the delete answered with 409 Conflict.
+*/
+func (fh fileHandler) adminDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	fID := mux.Vars(r)["fileID"]
+	unlock, ok := fh.locks.lockWithin(fID, adminDeleteWait)
+	if !ok {
+		e := "Upload is still locked by another request"
+		log.Println(e)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(e))
+		return
+	}
+	defer unlock()
+	err := fh.deleteUpload(fID)
+	if err == errFileNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error while deleting file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Upload %s deleted by admin\n", fID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminAuth lets only requests with the admin token as bearer token through.
+func (fh fileHandler) adminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(fh.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="uploader admin"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func adminList(t *testing.T, ts string, query url.Values) adminUploadList {
+	res, body := download(t, ts+"/admin/uploads?"+query.Encode(), map[string]string{"Authorization": "Bearer secret"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("For listing with %s expected status %d got %d: %s", query.Encode(), http.StatusOK,
+			res.StatusCode, body)
+	}
+	var list adminUploadList
+	err := json.Unmarshal([]byte(body), &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func uploadIDs(list adminUploadList) []string {
+	ids := []string{}
+	for _, u := range list.Uploads {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func TestAdminList(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.adminToken = "secret"
+	ts := serve(t, fh)
+
+	uploads := []struct {
+		filename string
+		length   string
+		body     string
+	}{
+		{"report.pdf", "10", "0123456789"},
+		{"photo.jpg", "40", "0123456789"},
+		{"report-2.pdf", "4", ""},
+		{"", "0", ""},
+	}
+	for _, u := range uploads {
+		header := map[string]string{"Upload-Length": u.length, "Content-Type": tusOffsetContentType}
+		if u.filename != "" {
+			header["Upload-Metadata"] = "filename " + base64.StdEncoding.EncodeToString([]byte(u.filename))
+		}
+		res := doRequest(t, "POST", ts.URL+"/files", header, u.body)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("For POST of %q expected status %d got %d", u.filename, http.StatusCreated, res.StatusCode)
+		}
+	}
+
+	list := adminList(t, ts.URL, nil)
+	if len(list.Uploads) != 4 || list.Next != "" {
+		t.Fatalf("Expected all 4 uploads on one page got %+v", list)
+	}
+	photo := list.Uploads[1]
+	if photo.Offset != 10 || *photo.Length != 40 || *photo.Progress != 25 || photo.Complete ||
+		photo.Metadata["filename"] != "photo.jpg" || photo.Owner != "ip:127.0.0.1" || photo.CreatedAt.IsZero() {
+		t.Errorf("Unexpected listing of the photo %+v", photo)
+	}
+	if empty := list.Uploads[3]; *empty.Progress != 100 || !empty.Complete {
+		t.Errorf("Expected the empty upload to be complete got %+v", empty)
+	}
+
+	tests := []struct {
+		name     string
+		query    url.Values
+		expected []string
+		next     string
+	}{
+		{"first page", url.Values{"limit": {"2"}}, []string{"1", "2"}, "2"},
+		{"second page", url.Values{"limit": {"2"}, "after": {"2"}}, []string{"3", "4"}, ""},
+		{"complete uploads", url.Values{"status": {"complete"}}, []string{"1", "4"}, ""},
+		{"incomplete uploads", url.Values{"status": {"incomplete"}}, []string{"2", "3"}, ""},
+		{"filename", url.Values{"filename": {"report"}}, []string{"1", "3"}, ""},
+		{"filename and status", url.Values{"filename": {"report"}, "status": {"incomplete"}}, []string{"3"}, ""},
+		{"created before", url.Values{"created_before": {time.Now().Add(-time.Hour).Format(time.RFC3339)}},
+			[]string{}, ""},
+	}
+	for _, test := range tests {
+		list := adminList(t, ts.URL, test.query)
+		ids := uploadIDs(list)
+		if len(ids) != len(test.expected) || list.Next != test.next {
+			t.Errorf("For %s expected %v, next %q got %v, next %q", test.name, test.expected, test.next, ids,
+				list.Next)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != test.expected[i] {
+				t.Errorf("For %s expected %v got %v", test.name, test.expected, ids)
+				break
+			}
+		}
+	}
+
+	for _, query := range []string{"status=done", "limit=0", "after=x", "created_before=yesterday"} {
+		res, _ := download(t, ts.URL+"/admin/uploads?"+query, map[string]string{"Authorization": "Bearer secret"})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("For %s expected status %d got %d", query, http.StatusBadRequest, res.StatusCode)
+		}
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.adminToken = "secret"
+	ts := serve(t, fh)
+
+	for _, auth := range []string{"", "secret", "Bearer wrong", "Basic c2VjcmV0"} {
+		res, _ := download(t, ts.URL+"/admin/uploads", map[string]string{"Authorization": auth})
+		if res.StatusCode != http.StatusUnauthorized || res.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("For Authorization %q expected status %d got %d", auth, http.StatusUnauthorized, res.StatusCode)
+		}
+	}
+
+	// Without a token the admin API doesn't exist.
+	fh.adminToken = ""
+	ts = serve(t, fh)
+	res, _ := download(t, ts.URL+"/admin/uploads", map[string]string{"Authorization": "Bearer "})
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For admin API without token expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestAdminDelete(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.adminToken = "secret"
+	ts := serve(t, fh)
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "10"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	// A request holding the lock delays the delete until it is done.
+	unlock, ok := fh.locks.tryLock("1")
+	if !ok {
+		t.Fatal("Expected to lock the upload")
+	}
+	time.AfterFunc(50*time.Millisecond, unlock)
+	start := time.Now()
+	res = doRequest(t, "DELETE", ts.URL+"/admin/uploads/1", auth, "")
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("For DELETE expected status %d got %d", http.StatusNoContent, res.StatusCode)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Errorf("Expected the delete to wait for the lock")
+	}
+	if _, err := fh.store.File("1"); err != errFileNotFound {
+		t.Errorf("Expected the upload to be deleted got %v", err)
+	}
+
+	res = doRequest(t, "DELETE", ts.URL+"/admin/uploads/1", auth, "")
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("For DELETE of a deleted upload expected status %d got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang-tutorial/exercises/golangbot/tusclient"
+)
+
+const uploadUsage = `Usage: golangbot upload [flags] <file> <url>
+
+Uploads file to the tus server whose uploads are created at url, for example http://localhost:8080/files, and prints
+the URL of the upload. An interrupted upload is resumed when the command is run again with the same file.
+
+Flags:
+`
+
+/*
+runUpload is the upload command, a client of the resumable file uploader built on the tusclient package. The URLs of
+uploads in progress are kept in -state, ~/.tusclient.json by default.
+*/
+func runUpload(args []string) {
+	fs := flag.NewFlagSet("upload", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), uploadUsage)
+		fs.PrintDefaults()
+	}
+	chunkSize := fs.Int64("chunk-size", tusclient.DefaultChunkSize, "bytes sent with each PATCH")
+	retries := fs.Int("retries", tusclient.DefaultMaxRetries, "how often a failed request is retried, 0 for never")
+	statePath := fs.String("state", "", "file which remembers uploads in progress, ~/.tusclient.json if empty")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *statePath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			log.Fatal("Error finding the home directory ", err)
+		}
+		*statePath = filepath.Join(home, ".tusclient.json")
+	}
+	// The client takes a MaxRetries of 0 for the default, it only turns retries off when negative.
+	if *retries == 0 {
+		*retries = -1
+	}
+
+	c := tusclient.Client{
+		ChunkSize:  *chunkSize,
+		MaxRetries: *retries,
+		Store:      &tusclient.FileStore{Path: *statePath},
+		Progress: func(offset, length int64) {
+			log.Printf("Uploaded %d of %d bytes\n", offset, length)
+		},
+	}
+	uploadURL, err := c.UploadFile(fs.Arg(1), fs.Arg(0))
+	if err != nil {
+		log.Fatal("Error uploading file ", err)
+	}
+	fmt.Println(uploadURL)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Values of the Upload-Concat header of the concatenation extension.
+const (
+	concatPartial = "partial"
+	concatFinal   = "final;"
+)
+
+/*
+parseConcatFinal returns the fileIDs of the partial uploads an Upload-Concat: final header lists, in order. The URLs
+may be absolute or just paths, only their last segment, the fileID, counts.
+*/
+func parseConcatFinal(concat string) ([]string, error) {
+	urls := strings.Fields(strings.TrimPrefix(concat, concatFinal))
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no partial uploads in %q", concat)
+	}
+	fileIDs := make([]string, 0, len(urls))
+	for _, u := range urls {
+		pu, err := url.Parse(u)
+		if err != nil {
+			return nil, err
+		}
+		fileID := path.Base(pu.Path)
+		if _, err := strconv.Atoi(fileID); err != nil || path.Base(path.Dir(pu.Path)) != "files" {
+			return nil, fmt.Errorf("%q is no upload", u)
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+	return fileIDs, nil
+}
+
+/*
+createFinalUpload creates the final upload of the concatenation extension. All partial uploads it lists have to be
+complete already, their blobs are copied one after the other into the blob of the final upload, which is complete as
+soon as it is created. The final upload remembers its partials by path, so HEAD can return them.
+*/
+func (fh fileHandler) createFinalUpload(w http.ResponseWriter, r *http.Request) {
+	partials, err := parseConcatFinal(r.Header.Get(headerUploadConcat))
+	if err != nil {
+		e := fmt.Sprintf("Improper upload concat %s", err)
+		log.Println(e)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(e))
+		return
+	}
+	metadata, err := parseMetadata(r.Header.Get(headerUploadMetadata))
+	if err != nil {
+		e := fmt.Sprintf("Improper upload metadata %s", err)
+		log.Println(e)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(e))
+		return
+	}
+	/*
+		The partials stay locked until they are copied, so neither a DELETE nor the expiration janitor removes one
+		halfway. A partial listed twice is locked once.
+	*/
+	locked := make(map[string]struct{})
+	for _, fileID := range partials {
+		if _, ok := locked[fileID]; ok {
+			continue
+		}
+		unlock, ok := fh.locks.tryLock(fileID)
+		if !ok {
+			e := fmt.Sprintf("Partial upload %s is locked by another request", fileID)
+			log.Println(e)
+			w.WriteHeader(http.StatusLocked)
+			w.Write([]byte(e))
+			return
+		}
+		defer unlock()
+		locked[fileID] = struct{}{}
+	}
+	length := 0
+	paths := make([]string, len(partials))
+	for i, fileID := range partials {
+		partial, err := fh.store.File(fileID)
+		if err == errFileNotFound {
+			e := fmt.Sprintf("Partial upload %s not found", fileID)
+			log.Println(e)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(e))
+			return
+		}
+		if err != nil {
+			log.Println("Error while fetching file", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if partial.uploadConcat != concatPartial {
+			e := fmt.Sprintf("Upload %s is not a partial upload", fileID)
+			log.Println(e)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(e))
+			return
+		}
+		if !*partial.uploadComplete {
+			e := fmt.Sprintf("Partial upload %s is not complete", fileID)
+			log.Println(e)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(e))
+			return
+		}
+		length += *partial.uploadLength
+		paths[i] = "/files/" + fileID
+	}
+	if fh.maxSize > 0 && length > fh.maxSize {
+		e := fmt.Sprintf("Upload length %d exceeds the maximum size %d", length, fh.maxSize)
+		log.Println(e)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(e))
+		return
+	}
+
+	off := 0
+	uc := false
+	f := file{
+		offset:         &off,
+		uploadLength:   &length,
+		uploadComplete: &uc,
+		uploadMetadata: metadata,
+		uploadConcat:   concatFinal + strings.Join(paths, " "),
+		owner:          fh.quotas.clientID(r),
+	}
+	if !fh.checkQuota(w, f) {
+		return
+	}
+	err = fh.hooks.preCreate(newHookEvent(hookPreCreate, r, "", f))
+	if err != nil {
+		rejectUpload(w, err)
+		return
+	}
+	fileID, ok := fh.createFile(w, f)
+	if !ok {
+		return
+	}
+	unlock, ok := fh.locks.tryLock(fileID)
+	if !ok {
+		e := "Upload is locked by another request"
+		log.Println(e)
+		w.WriteHeader(http.StatusLocked)
+		w.Write([]byte(e))
+		return
+	}
+	defer unlock()
+	err = fh.concatenate(fileID, partials, f)
+	if err != nil {
+		log.Printf("Error while concatenating into %s %s\n", fileID, err)
+		fh.discardUpload(fileID)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	fh.hooks.notify(newHookEvent(hookPostFinish, r, fileID, f))
+	w.Header().Set(headerLocation, fh.fileLocation(r, fileID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+/*
+concatenate copies the blobs of the partials into the blob of the final upload f, which got fileID, and commits it as
+complete.
+*/
+func (fh fileHandler) concatenate(fileID string, partials []string, f file) error {
+	var written int64
+	for _, partial := range partials {
+		n, err := fh.copyBlob(fileID, partial, written)
+		if err != nil {
+			return fmt.Errorf("copying %s %s", partial, err)
+		}
+		written += n
+	}
+	if written != int64(*f.uploadLength) {
+		return fmt.Errorf("copied %d bytes instead of %d", written, *f.uploadLength)
+	}
+	err := fh.finish(fileID)
+	if err != nil {
+		return err
+	}
+	*f.offset = int(written)
+	*f.uploadComplete = true
+	f.fileID, _ = strconv.Atoi(fileID)
+	return fh.store.UpdateFile(f)
+}
+
+// copyBlob appends the blob of the upload from to the blob of the upload to, which holds offset bytes already.
+func (fh fileHandler) copyBlob(to, from string, offset int64) (int64, error) {
+	br, err := fh.blobs.Open(from)
+	if err != nil {
+		return 0, err
+	}
+	defer br.Close()
+	return fh.blobs.WriteAt(to, br, offset)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Upload-Metadata keys the download takes its Content-Type and filename from, the ones tus clients commonly send.
+const (
+	metadataFileType = "filetype"
+	metadataFileName = "filename"
+)
+
+/*
+fileDownloadHandler serves a completed upload. The response is always an attachment, so a file which claims to be
+HTML is saved instead of being rendered on the uploader's origin. http.ServeContent takes care of Range and If-Range,
+the ETag it validates against changes whenever the record does. Uploads still in progress are 423 Locked.
+*/
+func (fh fileHandler) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	fID := vars["fileID"]
+	file, err := fh.store.File(fID)
+	if err == errFileNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error while fetching file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !*file.uploadComplete {
+		e := "Upload is still in progress"
+		w.WriteHeader(http.StatusLocked)
+		w.Write([]byte(e))
+		return
+	}
+	br, err := fh.blobs.Open(fID)
+	if err == errBlobNotFound {
+		log.Println("Blob of completed upload is missing", fID)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error while opening file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer br.Close()
+
+	w.Header().Set(headerContentType, downloadContentType(file.uploadMetadata[metadataFileType]))
+	disposition := "attachment"
+	if name := file.uploadMetadata[metadataFileName]; name != "" {
+		if d := mime.FormatMediaType(disposition, map[string]string{"filename": name}); d != "" {
+			disposition = d
+		}
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("ETag", fmt.Sprintf("\"%d-%d\"", file.fileID, file.modifiedAt.UnixNano()))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", file.modifiedAt, br)
+}
+
+// downloadContentType returns the media type a client declared for its upload if it is a valid one.
+func downloadContentType(fileType string) string {
+	mediaType, params, err := mime.ParseMediaType(fileType)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mime.FormatMediaType(mediaType, params)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+/*
+expires returns when the incomplete upload f expires: the expiration of the uploader after its last change. It
+returns the zero time for complete uploads and when uploads never expire.
+*/
+func (fh fileHandler) expires(f file) time.Time {
+	if fh.expiration <= 0 || *f.uploadComplete {
+		return time.Time{}
+	}
+	return f.modifiedAt.Add(fh.expiration)
+}
+
+func (fh fileHandler) expired(f file, now time.Time) bool {
+	expires := fh.expires(f)
+	return !expires.IsZero() && !now.Before(expires)
+}
+
+// setUploadExpires adds the Upload-Expires header of the expiration extension unless expires is the zero time.
+func setUploadExpires(w http.ResponseWriter, expires time.Time) {
+	if expires.IsZero() {
+		return
+	}
+	w.Header().Set(headerUploadExpires, expires.UTC().Format(http.TimeFormat))
+}
+
+// deleteUpload removes the record of fileID and then its blob. The caller has to hold the lock of the upload.
+func (fh fileHandler) deleteUpload(fileID string) error {
+	err := fh.store.DeleteFile(fileID)
+	if err != nil {
+		return err
+	}
+	return fh.blobs.Delete(fileID)
+}
+
+/*
+discardUpload deletes the upload of fileID which a POST created but failed to fill, so it doesn't count against the
+quota of the client until it expires. The client never learned its Location anyway.
+*/
+func (fh fileHandler) discardUpload(fileID string) {
+	err := fh.deleteUpload(fileID)
+	if err != nil {
+		log.Println("Error while deleting file", fileID, err)
+	}
+}
+
+/*
+collectExpired deletes the uploads which expired by now and returns how many it deleted. Uploads locked by a request
+are skipped, that request is about to change them anyway, and every upload is checked again under the lock because a
+PATCH may have refreshed it since it was listed.
+*/
+func (fh fileHandler) collectExpired(now time.Time) (int, error) {
+	fileIDs, err := fh.store.ExpiredFiles(now.Add(-fh.expiration))
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, fileID := range fileIDs {
+		unlock, ok := fh.locks.tryLock(fileID)
+		if !ok {
+			continue
+		}
+		f, err := fh.store.File(fileID)
+		if err == nil && fh.expired(f, now) {
+			err = fh.deleteUpload(fileID)
+			if err == nil {
+				deleted++
+			}
+		}
+		unlock()
+		if err != nil && err != errFileNotFound {
+			log.Printf("Error while deleting expired upload %s %s\n", fileID, err)
+		}
+	}
+	return deleted, nil
+}
+
+/*
+runJanitor collects expired uploads every interval until stop is closed. It does nothing if uploads never expire.
+*/
+func (fh fileHandler) runJanitor(interval time.Duration, stop <-chan struct{}) {
+	if fh.expiration <= 0 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			deleted, err := fh.collectExpired(now)
+			if err != nil {
+				log.Println("Error while collecting expired uploads", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Deleted %d expired uploads\n", deleted)
+			}
+		}
+	}
+}
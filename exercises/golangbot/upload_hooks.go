@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Hook points of the uploader. A pre-create hook runs before an upload is created and can reject it. post-receive hooks
+run after a PATCH or a POST with creation-with-upload committed bytes and post-finish hooks once an upload is complete.
+*/
+const (
+	hookPreCreate   = "pre-create"
+	hookPostReceive = "post-receive"
+	hookPostFinish  = "post-finish"
+)
+
+// hookTimeout bounds how long an external command or a webhook may take.
+const hookTimeout = 30 * time.Second
+
+// hookEvent is what a hook is told about an upload. Hooks outside the process receive it as JSON.
+type hookEvent struct {
+	Type    string      `json:"type"`
+	Upload  hookUpload  `json:"upload"`
+	Request hookRequest `json:"request"`
+}
+
+// hookUpload describes the upload of a hookEvent. ID is empty in pre-create, the upload doesn't exist yet.
+type hookUpload struct {
+	ID       string            `json:"id"`
+	Offset   int               `json:"offset"`
+	Length   *int              `json:"length"` // null while the length is deferred
+	Metadata map[string]string `json:"metadata"`
+	Concat   string            `json:"concat,omitempty"`
+}
+
+/*
+hookRequest is the request which triggered a hookEvent, so hooks can decide by the client and its headers. Header only
+holds the headers of hookHeaders, credentials such as Authorization and Cookie are none of a hook's business.
+*/
+type hookRequest struct {
+	Method     string      `json:"method"`
+	URI        string      `json:"uri"`
+	RemoteAddr string      `json:"remote_addr"`
+	Header     http.Header `json:"header"`
+}
+
+// hookHeaders are the request headers hooks are told about.
+var hookHeaders = []string{
+	headerTusResumable, headerUploadLength, headerUploadDeferLength, headerUploadOffset, headerUploadMetadata,
+	headerUploadConcat, headerUploadChecksum, headerContentType, "Content-Length", "User-Agent", "Origin",
+	"X-Forwarded-For",
+}
+
+func newHookEvent(hookType string, r *http.Request, fileID string, f file) hookEvent {
+	header := make(http.Header)
+	for _, name := range hookHeaders {
+		if values, ok := r.Header[name]; ok {
+			header[name] = values
+		}
+	}
+	return hookEvent{
+		Type: hookType,
+		Upload: hookUpload{
+			ID:       fileID,
+			Offset:   *f.offset,
+			Length:   f.uploadLength,
+			Metadata: f.uploadMetadata,
+			Concat:   f.uploadConcat,
+		},
+		Request: hookRequest{
+			Method:     r.Method,
+			URI:        r.RequestURI,
+			RemoteAddr: r.RemoteAddr,
+			Header:     header,
+		},
+	}
+}
+
+/*
+hookRejection is returned by a pre-create hook which turns the upload down. The uploader answers the POST with status
+and message.
+*/
+type hookRejection struct {
+	status  int
+	message string
+}
+
+func (hr *hookRejection) Error() string {
+	return fmt.Sprintf("upload rejected with %d %s", hr.status, hr.message)
+}
+
+// hook is run at a hook point. Any error but a hookRejection is a failure of the hook itself.
+type hook interface {
+	run(e hookEvent) error
+}
+
+// hookFunc lets a Go function be a hook.
+type hookFunc func(e hookEvent) error
+
+func (hf hookFunc) run(e hookEvent) error {
+	return hf(e)
+}
+
+/*
+commandHook runs an external command with the hook type as its only argument and the event as JSON on stdin. A
+pre-create command rejects the upload by exiting with a status other than 0. It may print {"status": 403, "message":
+"..."} to choose the answer of the uploader, otherwise the upload is rejected with 403 and whatever the command
+printed.
+*/
+type commandHook struct {
+	path string
+}
+
+func (ch commandHook) run(e hookEvent) error {
+	in, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ch.path, e.Type)
+	cmd.Stdin = bytes.NewReader(in)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err = cmd.Run()
+	if _, ok := err.(*exec.ExitError); !ok || ctx.Err() != nil {
+		return err
+	}
+	hr := hookRejection{status: http.StatusForbidden, message: strings.TrimSpace(out.String())}
+	var answer struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(out.Bytes(), &answer) == nil {
+		if answer.Status >= 400 && answer.Status < 600 {
+			hr.status = answer.Status
+		}
+		hr.message = answer.Message
+	}
+	return &hr
+}
+
+/*
+webhook posts the event as JSON to url, with the hook type in the Hook-Name header. A pre-create webhook rejects the
+upload by answering with a 4xx status, the uploader passes it and the body of the answer on to the client. Any other
+status but 2xx is a failure of the webhook.
+*/
+type webhook struct {
+	url    string
+	client *http.Client
+}
+
+func (wh webhook) run(e hookEvent) error {
+	in, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", wh.url, bytes.NewReader(in))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Hook-Name", e.Type)
+	res, err := wh.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 4096))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	if res.StatusCode >= 400 && res.StatusCode < 500 {
+		return &hookRejection{status: res.StatusCode, message: strings.TrimSpace(string(body))}
+	}
+	return fmt.Errorf("webhook %s answered %d", wh.url, res.StatusCode)
+}
+
+/*
+uploadHooks holds the hooks of every hook point. pre-create hooks run in the request and the first one failing decides.
+post-receive and post-finish hooks run in the background after the request, so a slow virus scan doesn't hold up the
+client, and their errors are only logged. The events of an upload wait in its queue for the ones before them, so hooks
+see them in the order the requests committed them. wait blocks until the background hooks are done. A nil
+*uploadHooks has no hooks, so a fileHandler doesn't need any.
+*/
+type uploadHooks struct {
+	mu     sync.Mutex
+	hooks  map[string][]hook
+	queues map[string][]hookEvent
+	wg     sync.WaitGroup
+}
+
+func newUploadHooks() *uploadHooks {
+	return &uploadHooks{hooks: make(map[string][]hook), queues: make(map[string][]hookEvent)}
+}
+
+func (uh *uploadHooks) add(hookType string, h hook) {
+	uh.mu.Lock()
+	defer uh.mu.Unlock()
+	uh.hooks[hookType] = append(uh.hooks[hookType], h)
+}
+
+func (uh *uploadHooks) get(hookType string) []hook {
+	if uh == nil {
+		return nil
+	}
+	uh.mu.Lock()
+	defer uh.mu.Unlock()
+	return uh.hooks[hookType]
+}
+
+// preCreate runs the pre-create hooks one after the other and returns the first error.
+func (uh *uploadHooks) preCreate(e hookEvent) error {
+	for _, h := range uh.get(hookPreCreate) {
+		err := h.run(e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+notify queues events, which all belong to one upload, and runs their hooks in the background after the events queued
+before them. Only one goroutine at a time delivers the events of an upload, it is started when its queue was empty.
+*/
+func (uh *uploadHooks) notify(events ...hookEvent) {
+	if uh == nil || len(events) == 0 {
+		return
+	}
+	fileID := events[0].Upload.ID
+	uh.mu.Lock()
+	defer uh.mu.Unlock()
+	queue, delivering := uh.queues[fileID]
+	uh.queues[fileID] = append(queue, events...)
+	if delivering {
+		return
+	}
+	uh.wg.Add(1)
+	go uh.deliver(fileID)
+}
+
+// deliver runs the hooks of the events queued for fileID until its queue is empty.
+func (uh *uploadHooks) deliver(fileID string) {
+	defer uh.wg.Done()
+	for {
+		uh.mu.Lock()
+		queue := uh.queues[fileID]
+		if len(queue) == 0 {
+			delete(uh.queues, fileID)
+			uh.mu.Unlock()
+			return
+		}
+		e := queue[0]
+		uh.queues[fileID] = queue[1:]
+		uh.mu.Unlock()
+		for _, h := range uh.get(e.Type) {
+			err := h.run(e)
+			if err != nil {
+				log.Printf("Error in %s hook of upload %s %s\n", e.Type, e.Upload.ID, err)
+			}
+		}
+	}
+}
+
+func (uh *uploadHooks) wait() {
+	if uh == nil {
+		return
+	}
+	uh.wg.Wait()
+}
+
+/*
+rejectUpload answers a POST whose pre-create hook failed: with the status and message of a rejection, with 500 for a
+hook which didn't work.
+*/
+func rejectUpload(w http.ResponseWriter, err error) {
+	if hr, ok := err.(*hookRejection); ok {
+		log.Println("Upload rejected by pre-create hook", hr.message)
+		w.WriteHeader(hr.status)
+		w.Write([]byte(hr.message))
+		return
+	}
+	log.Println("Error in pre-create hook", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
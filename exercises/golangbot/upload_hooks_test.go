@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordHook returns a hook which appends a line for every event it sees to events.
+func recordHook(mu *sync.Mutex, events *[]string) hookFunc {
+	return func(e hookEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		*events = append(*events, fmt.Sprintf("%s %s %d %s", e.Type, e.Upload.ID, e.Upload.Offset,
+			e.Upload.Metadata["filename"]))
+		return nil
+	}
+}
+
+func TestHooks(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.hooks = newUploadHooks()
+	// The first post-receive hook of the POST is held up until the PATCH is answered, whose events have to wait.
+	patched := make(chan struct{})
+	fh.hooks.add(hookPostReceive, hookFunc(func(e hookEvent) error {
+		if e.Upload.Offset == 5 {
+			<-patched
+		}
+		return nil
+	}))
+	var mu sync.Mutex
+	var events []string
+	for _, hookType := range []string{hookPreCreate, hookPostReceive, hookPostFinish} {
+		fh.hooks.add(hookType, recordHook(&mu, &events))
+	}
+	fh.hooks.add(hookPreCreate, hookFunc(func(e hookEvent) error {
+		if strings.HasSuffix(e.Upload.Metadata["filename"], ".exe") {
+			return &hookRejection{status: http.StatusUnavailableForLegalReasons, message: "No executables"}
+		}
+		return nil
+	}))
+	var leaked []string
+	fh.hooks.add(hookPreCreate, hookFunc(func(e hookEvent) error {
+		for _, name := range []string{"Authorization", "Cookie"} {
+			if e.Request.Header.Get(name) != "" {
+				leaked = append(leaked, name)
+			}
+		}
+		return nil
+	}))
+	ts := serve(t, fh)
+
+	req, err := http.NewRequest("POST", ts.URL+"/files", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(headerTusResumable, tusVersion)
+	req.Header.Set(headerUploadLength, "10")
+	req.Header.Set(headerUploadMetadata, encodeMetadata(map[string]string{"filename": "virus.exe"}))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnavailableForLegalReasons || string(body) != "No executables" {
+		t.Fatalf("For rejected POST expected status %d and the message of the hook got %d %q",
+			http.StatusUnavailableForLegalReasons, res.StatusCode, body)
+	}
+
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "10",
+		"Upload-Metadata": encodeMetadata(map[string]string{"filename": "a.txt"}),
+		"Content-Type":    tusOffsetContentType, "Authorization": "Bearer secret", "Cookie": "session=secret"},
+		"hello")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	res = doRequest(t, "PATCH", ts.URL+"/files/1", map[string]string{"Upload-Offset": "5"}, "world")
+	close(patched)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("For PATCH expected status %d got %d", http.StatusNoContent, res.StatusCode)
+	}
+	fh.hooks.wait()
+
+	expected := []string{
+		"pre-create  0 virus.exe",
+		"pre-create  0 a.txt",
+		"post-receive 1 5 a.txt",
+		"post-receive 1 10 a.txt",
+		"post-finish 1 10 a.txt",
+	}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected hook events\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+	if len(leaked) > 0 {
+		t.Errorf("Expected hooks not to see the credentials of the client got %v", leaked)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	wh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e hookEvent
+		err := json.NewDecoder(r.Body).Decode(&e)
+		if err != nil || r.Header.Get("Hook-Name") != e.Type {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		events = append(events, fmt.Sprintf("%s %s %d", e.Type, e.Upload.ID, e.Upload.Offset))
+		mu.Unlock()
+		switch {
+		case e.Type == hookPreCreate && *e.Upload.Length > 100:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Too large"))
+		case e.Type == hookPostReceive:
+			// A failing post hook is only logged.
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(wh.Close)
+	fh := newTestHandler(t)
+	fh.hooks = newUploadHooks()
+	for _, hookType := range []string{hookPreCreate, hookPostReceive, hookPostFinish} {
+		fh.hooks.add(hookType, webhook{url: wh.URL, client: http.DefaultClient})
+	}
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "1000"}, "")
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("For POST rejected by the webhook expected status %d got %d", http.StatusForbidden, res.StatusCode)
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "5",
+		"Content-Type": tusOffsetContentType}, "hello")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fh.hooks.wait()
+
+	expected := "pre-create  0,pre-create  0,post-receive 1 5,post-finish 1 5"
+	if strings.Join(events, ",") != expected {
+		t.Errorf("Expected webhook events %s got %s", expected, strings.Join(events, ","))
+	}
+
+	wh.Close()
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "5"}, "")
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("For POST with an unreachable webhook expected status %d got %d", http.StatusInternalServerError,
+			res.StatusCode)
+	}
+}
+
+func TestCommandHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	// The hook keeps the last event of every hook type and rejects uploads of 1000 bytes.
+	script := fmt.Sprintf(`#!/bin/sh
+cat > %s/"$1".json
+if grep -q '"length":1000' %s/"$1".json; then
+	echo '{"status": 422, "message": "No thousands"}'
+	exit 1
+fi
+`, dir, dir)
+	path := filepath.Join(dir, "hook.sh")
+	err = ioutil.WriteFile(path, []byte(script), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fh := newTestHandler(t)
+	fh.hooks = newUploadHooks()
+	for _, hookType := range []string{hookPreCreate, hookPostReceive, hookPostFinish} {
+		fh.hooks.add(hookType, commandHook{path: path})
+	}
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "1000"}, "")
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("For POST rejected by the command expected status %d got %d", http.StatusUnprocessableEntity,
+			res.StatusCode)
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "5",
+		"Content-Type": tusOffsetContentType}, "hello")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	fh.hooks.wait()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, hookPostFinish+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e hookEvent
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != hookPostFinish || e.Upload.ID != "1" || e.Upload.Offset != 5 || e.Request.Method != "POST" {
+		t.Errorf("Unexpected post-finish event %s", data)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var errOffsetMismatch = errors.New("offset does not match")
+
+/*
+uploadLocks hands out one lock per upload, so only one request at a time writes to the file of an upload and moves its
+offset. The locks live in this process only, instances sharing a store are kept apart by UpdateFileAt instead.
+*/
+type uploadLocks struct {
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+// uploadLock is a mutex which can be tried without blocking. refs counts the requests holding or trying it.
+type uploadLock struct {
+	held chan struct{}
+	refs int
+}
+
+func newUploadLocks() *uploadLocks {
+	return &uploadLocks{locks: make(map[string]*uploadLock)}
+}
+
+/*
+tryLock locks the upload of fileID and returns the function which unlocks it again. It returns false without waiting
+if another request holds the lock already.
+*/
+func (ul *uploadLocks) tryLock(fileID string) (func(), bool) {
+	l := ul.acquire(fileID)
+	select {
+	case l.held <- struct{}{}:
+		return ul.unlocker(fileID, l), true
+	default:
+		ul.release(fileID, l)
+		return nil, false
+	}
+}
+
+// lockWithin is tryLock, but it waits up to timeout for the request holding the lock to finish.
+func (ul *uploadLocks) lockWithin(fileID string, timeout time.Duration) (func(), bool) {
+	l := ul.acquire(fileID)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case l.held <- struct{}{}:
+		return ul.unlocker(fileID, l), true
+	case <-timer.C:
+		ul.release(fileID, l)
+		return nil, false
+	}
+}
+
+// acquire returns the lock of fileID, creating it if nobody uses it yet, and counts the caller as one more user.
+func (ul *uploadLocks) acquire(fileID string) *uploadLock {
+	ul.mu.Lock()
+	defer ul.mu.Unlock()
+	l, ok := ul.locks[fileID]
+	if !ok {
+		l = &uploadLock{held: make(chan struct{}, 1)}
+		ul.locks[fileID] = l
+	}
+	l.refs++
+	return l
+}
+
+func (ul *uploadLocks) unlocker(fileID string, l *uploadLock) func() {
+	return func() {
+		<-l.held
+		ul.release(fileID, l)
+	}
+}
+
+// release drops the lock of fileID from the map once nobody uses it any more.
+func (ul *uploadLocks) release(fileID string, l *uploadLock) {
+	ul.mu.Lock()
+	defer ul.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(ul.locks, fileID)
+	}
+}
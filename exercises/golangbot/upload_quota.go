@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// clientUsage is what the uploads of one client take up: how many are still incomplete and how many bytes all hold.
+type clientUsage struct {
+	uploads int
+	bytes   int64 // the length of every upload, or its offset while the length is deferred
+}
+
+/*
+uploadQuotas limits what a single client may upload: maxUploads incomplete uploads at a time and maxBytes over all of
+its uploads, complete ones included. A limit of 0 is no limit. Clients are told apart by clientID.
+
+Checking the quota and creating the upload happen under mu, so concurrent POSTs of a client can't both squeeze into
+the last bytes of its quota. That holds within one process, instances sharing a store may overshoot by a few uploads.
+*/
+type uploadQuotas struct {
+	maxUploads     int
+	maxBytes       int64
+	identityHeader string
+	mu             sync.Mutex
+}
+
+// quotaExceeded is the answer to a request which would go over a quota.
+type quotaExceeded struct {
+	status  int
+	message string
+}
+
+func (qe *quotaExceeded) Error() string {
+	return qe.message
+}
+
+/*
+clientID returns whom the upload r creates counts against. That is the identity in identityHeader, which a proxy in
+front of the uploader sets after authenticating the client, or else the IP address of the client. The proxy has to
+drop identityHeader from the requests of clients, otherwise they can pick any identity.
+*/
+func (uq *uploadQuotas) clientID(r *http.Request) string {
+	if uq != nil && uq.identityHeader != "" {
+		if id := r.Header.Get(uq.identityHeader); id != "" {
+			return "user:" + id
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+/*
+check returns a quotaExceeded if the owner of f can't create it. Uploads over the number of uploads are answered with
+429 Too Many Requests, the client may try again once one of them is complete. Uploads over the bytes quota get 413
+Request Entity Too Large.
+*/
+func (uq *uploadQuotas) check(store FileStore, f file) error {
+	if uq.maxUploads <= 0 && uq.maxBytes <= 0 {
+		return nil
+	}
+	u, err := store.Usage(f.owner)
+	if err != nil {
+		return err
+	}
+	if uq.maxUploads > 0 && u.uploads >= uq.maxUploads {
+		return &quotaExceeded{
+			status:  http.StatusTooManyRequests,
+			message: fmt.Sprintf("Client has %d incomplete uploads, the limit is %d", u.uploads, uq.maxUploads),
+		}
+	}
+	length := int64(*f.offset)
+	if f.uploadLength != nil {
+		length = int64(*f.uploadLength)
+	}
+	if uq.maxBytes > 0 && strings.HasPrefix(f.uploadConcat, concatFinal) {
+		covered, err := concatenatedBytes(store, f)
+		if err != nil {
+			return err
+		}
+		length -= covered
+	}
+	if uq.maxBytes > 0 && u.bytes+length > uq.maxBytes {
+		return &quotaExceeded{
+			status: http.StatusRequestEntityTooLarge,
+			message: fmt.Sprintf("Upload length %d exceeds the quota of the client, %d of %d bytes are left", length,
+				uq.maxBytes-u.bytes, uq.maxBytes),
+		}
+	}
+	return nil
+}
+
+/*
+concatenatedBytes returns how many bytes of the final upload f are held by partial uploads of its owner. Those count
+against the quota already, so creating f only needs quota for the rest, otherwise a client whose partials fill its
+quota could never concatenate them. Once created f counts in full until the client deletes the partials.
+*/
+func concatenatedBytes(store FileStore, f file) (int64, error) {
+	fileIDs, err := parseConcatFinal(f.uploadConcat)
+	if err != nil {
+		return 0, err
+	}
+	var covered int64
+	seen := make(map[string]bool)
+	for _, fileID := range fileIDs {
+		if seen[fileID] {
+			continue
+		}
+		seen[fileID] = true
+		partial, err := store.File(fileID)
+		if err == errFileNotFound {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if partial.owner == f.owner && partial.uploadLength != nil {
+			covered += int64(*partial.uploadLength)
+		}
+	}
+	return covered, nil
+}
+
+/*
+bytesLeft returns how many more bytes owner may upload. A PATCH of an upload whose length is deferred checks it, the
+bytes it sends were not counted when the upload was created.
+*/
+func (uq *uploadQuotas) bytesLeft(store FileStore, owner string) (int64, bool, error) {
+	if uq == nil || uq.maxBytes <= 0 {
+		return 0, false, nil
+	}
+	u, err := store.Usage(owner)
+	if err != nil {
+		return 0, false, err
+	}
+	left := uq.maxBytes - u.bytes
+	if left < 0 {
+		left = 0
+	}
+	return left, true, nil
+}
+
+/*
+checkQuota answers the request itself and returns false if the owner of f has no quota left for it. Handlers call it
+before the pre-create hooks, so those don't run for uploads which are refused anyway. createFile checks again under
+mu, a concurrent POST of the same client may have used up the quota in between.
+*/
+func (fh fileHandler) checkQuota(w http.ResponseWriter, f file) bool {
+	if fh.quotas == nil {
+		return true
+	}
+	err := fh.quotas.check(fh.store, f)
+	if qe, ok := err.(*quotaExceeded); ok {
+		log.Println(qe.message)
+		w.WriteHeader(qe.status)
+		w.Write([]byte(qe.message))
+		return false
+	}
+	if err != nil {
+		log.Println("Error while checking quota", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+/*
+createFile creates the upload f in the store if its owner has quota left and returns its fileID. Otherwise it answers
+the request itself and returns false.
+*/
+func (fh fileHandler) createFile(w http.ResponseWriter, f file) (string, bool) {
+	if fh.quotas != nil {
+		fh.quotas.mu.Lock()
+		defer fh.quotas.mu.Unlock()
+		if !fh.checkQuota(w, f) {
+			return "", false
+		}
+	}
+	fileID, err := fh.store.CreateFile(f)
+	if err != nil {
+		log.Println("Error creating file in DB", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
+	}
+	return fileID, true
+}
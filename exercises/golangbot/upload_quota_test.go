@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestUploadQuotas(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.quotas = &uploadQuotas{maxUploads: 2, maxBytes: 100, identityHeader: "X-Uploader-Identity"}
+	// Pre-create hooks only see the uploads which are created.
+	fh.hooks = newUploadHooks()
+	preCreated := 0
+	fh.hooks.add(hookPreCreate, hookFunc(func(e hookEvent) error {
+		preCreated++
+		return nil
+	}))
+	ts := serve(t, fh)
+
+	tests := []struct {
+		name     string
+		header   map[string]string
+		body     string
+		expected int
+	}{
+		{"first upload", map[string]string{"Upload-Length": "60"}, "", http.StatusCreated},
+		{"upload over the bytes left", map[string]string{"Upload-Length": "50"}, "", http.StatusRequestEntityTooLarge},
+		{"upload within the bytes left", map[string]string{"Upload-Length": "40",
+			"Content-Type": tusOffsetContentType}, digits40, http.StatusCreated},
+		{"upload of another client", map[string]string{"Upload-Length": "100",
+			"X-Uploader-Identity": "alice"}, "", http.StatusCreated},
+		{"second upload of alice", map[string]string{"Upload-Defer-Length": "1", "X-Uploader-Identity": "alice"}, "",
+			http.StatusCreated},
+		{"upload over the number of uploads", map[string]string{"Upload-Defer-Length": "1",
+			"X-Uploader-Identity": "alice"}, "", http.StatusTooManyRequests},
+	}
+	for _, test := range tests {
+		res := doRequest(t, "POST", ts.URL+"/files", test.header, test.body)
+		if res.StatusCode != test.expected {
+			t.Errorf("For %s expected status %d got %d", test.name, test.expected, res.StatusCode)
+		}
+	}
+	// The client of the IP address has used up its 100 bytes.
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "1"}, "")
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("For upload of an exhausted client expected status %d got %d", http.StatusRequestEntityTooLarge,
+			res.StatusCode)
+	}
+
+	// Completing an upload makes room for another one.
+	res = doRequest(t, "PATCH", ts.URL+"/files/3", map[string]string{"Upload-Offset": "0"}, digits100)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("For PATCH expected status %d got %d", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Defer-Length": "1",
+		"X-Uploader-Identity": "alice"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("For upload after completing one expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	if preCreated != 5 {
+		t.Errorf("For pre-create hooks expected 5 calls got %d", preCreated)
+	}
+}
+
+func TestUploadQuotaDeferredLength(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.quotas = &uploadQuotas{maxBytes: 100}
+	ts := serve(t, fh)
+
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": "60"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Defer-Length": "1"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST with deferred length expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Defer-Length": "1",
+		"Content-Type": tusOffsetContentType}, digits100)
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("For POST with deferred length and a body over the quota expected status %d got %d",
+			http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+
+	tests := []struct {
+		name     string
+		header   map[string]string
+		body     string
+		expected int
+	}{
+		{"chunk within the quota", map[string]string{"Upload-Offset": "0"}, digits40[:30], http.StatusNoContent},
+		{"chunk over the quota", map[string]string{"Upload-Offset": "30"}, digits40[:20],
+			http.StatusRequestEntityTooLarge},
+		{"length over the quota", map[string]string{"Upload-Offset": "30", "Upload-Length": "50"}, "",
+			http.StatusRequestEntityTooLarge},
+		{"length within the quota", map[string]string{"Upload-Offset": "30", "Upload-Length": "40"}, digits40[:10],
+			http.StatusNoContent},
+	}
+	for _, test := range tests {
+		res := doRequest(t, "PATCH", ts.URL+"/files/2", test.header, test.body)
+		if res.StatusCode != test.expected {
+			t.Errorf("For %s expected status %d got %d", test.name, test.expected, res.StatusCode)
+		}
+	}
+	f, err := fh.store.File("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 40 || !*f.uploadComplete {
+		t.Errorf("Expected upload to be complete at offset 40 got offset %d, complete %t", *f.offset,
+			*f.uploadComplete)
+	}
+}
+
+func TestUploadQuotaConcatenation(t *testing.T) {
+	fh := newTestHandler(t)
+	fh.quotas = &uploadQuotas{maxBytes: 100}
+	ts := serve(t, fh)
+
+	for _, body := range []string{digits40, digits100[:60]} {
+		res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Length": strconv.Itoa(len(body)),
+			"Upload-Concat": "partial", "Content-Type": tusOffsetContentType}, body)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("For POST of partial upload expected status %d got %d", http.StatusCreated, res.StatusCode)
+		}
+	}
+	// The partials fill the quota, the final upload only needs quota for bytes which are not in them.
+	res := doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Concat": "final;/files/1 /files/2"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST of final upload expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	// The final upload counts in full, so the partials can't be concatenated again.
+	res = doRequest(t, "POST", ts.URL+"/files", map[string]string{"Upload-Concat": "final;/files/1 /files/2"}, "")
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("For second POST of final upload expected status %d got %d", http.StatusRequestEntityTooLarge,
+			res.StatusCode)
+	}
+}
+
+const (
+	digits40  = "0123456789012345678901234567890123456789"
+	digits100 = digits40 + digits40 + "01234567890123456789"
+)
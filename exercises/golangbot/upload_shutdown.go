@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+serveUntil serves srv on l until stop is closed and then shuts it down gracefully. No new connections are accepted,
+requests in flight get up to timeout to finish. A PATCH which is still streaming its body after that has its
+connection closed: the read fails and the handler commits the offset of the bytes already written, like for any
+dropped connection, so the client resumes from there. serveUntil returns once every handler is done, only then may
+the stores be closed. The error is the one of srv.Serve if the server failed before stop was closed.
+
+srv.Close doesn't wait for the handlers, so serveUntil counts the connections itself. The server reports a new
+connection before Serve can return and a closed one only after its last handler returned, which makes the count
+safe to wait for once Serve returned. Handlers mustn't hijack their connection, it would stop counting early.
+*/
+func serveUntil(srv *http.Server, l net.Listener, stop <-chan struct{}, timeout time.Duration) error {
+	var conns sync.WaitGroup
+	connState := srv.ConnState
+	srv.ConnState = func(c net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			conns.Add(1)
+		case http.StateHijacked, http.StateClosed:
+			conns.Done()
+		}
+		if connState != nil {
+			connState(c, state)
+		}
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(l)
+	}()
+	select {
+	case err := <-served:
+		return err
+	case <-stop:
+	}
+
+	log.Println("Shutting down, waiting for requests in flight")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		log.Printf("Requests still in flight after %s, closing their connections\n", timeout)
+		srv.Close()
+	}
+	<-served
+	conns.Wait()
+	return nil
+}
+
+/*
+stopOnSignals closes stop on the first of signals, which starts the graceful shutdown, and calls exit on the second,
+for whoever doesn't want to wait for the requests in flight after all.
+*/
+func stopOnSignals(signals <-chan os.Signal, stop chan<- struct{}, exit func(code int)) {
+	log.Println("Received", <-signals)
+	close(stop)
+	log.Println("Received", <-signals, "again, exiting without waiting for requests in flight")
+	exit(1)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+/*
+startShutdownTest serves fh until the returned stop channel is closed, creates an upload of 10 bytes and starts a PATCH
+which sends hello and then waits for the rest of its body on the returned pipe. Its response is sent on the last
+channel, startShutdownTest returns once the server received hello.
+*/
+func startShutdownTest(t *testing.T, fh fileHandler, timeout time.Duration) (chan struct{}, chan error, *io.PipeWriter,
+	chan *http.Response) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + l.Addr().String() + "/files"
+	stop := make(chan struct{})
+	served := make(chan error, 1)
+	go func() {
+		served <- serveUntil(&http.Server{Handler: fh.router()}, l, stop, timeout)
+	}()
+
+	res := doRequest(t, "POST", url, map[string]string{"Upload-Length": "10"}, "")
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("For POST expected status %d got %d", http.StatusCreated, res.StatusCode)
+	}
+	pr, pw := io.Pipe()
+	t.Cleanup(func() {
+		pw.Close()
+	})
+	req, err := http.NewRequest("PATCH", url+"/1", pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.ContentLength = 10
+	req.Header.Set(headerTusResumable, tusVersion)
+	req.Header.Set(headerContentType, tusOffsetContentType)
+	req.Header.Set(headerUploadOffset, "0")
+	patched := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			patched <- nil
+			return
+		}
+		res.Body.Close()
+		patched <- res
+	}()
+	_, err = pw.Write([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		n, err := fh.blobs.Size("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 5 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return stop, served, pw, patched
+}
+
+func TestShutdownWaitsForRequests(t *testing.T) {
+	fh := newTestHandler(t)
+	stop, served, pw, patched := startShutdownTest(t, fh, 10*time.Second)
+
+	close(stop)
+	select {
+	case err := <-served:
+		t.Fatalf("Server stopped with %v while a PATCH was in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	pw.Write([]byte("world"))
+	res := <-patched
+	if res == nil || res.StatusCode != http.StatusNoContent || res.Header.Get("Upload-Offset") != "10" {
+		t.Fatalf("Expected the PATCH in flight to complete the upload got %+v", res)
+	}
+	err := <-served
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdownCommitsCutOffPatch(t *testing.T) {
+	fh := newTestHandler(t)
+	stop, served, pw, patched := startShutdownTest(t, fh, 100*time.Millisecond)
+
+	close(stop)
+	err := <-served
+	if err != nil {
+		t.Fatal(err)
+	}
+	// serveUntil returned, so the handler of the PATCH is done and the store may be closed.
+	f, err := fh.store.File("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 5 {
+		t.Errorf("Expected the 5 bytes received before the deadline to be committed got offset %d", *f.offset)
+	}
+	// The client only gives up on the request once it stopped sending the body.
+	pw.Close()
+	if res := <-patched; res != nil {
+		t.Errorf("Expected the connection of the PATCH to be closed got status %d", res.StatusCode)
+	}
+}
+
+func TestStopOnSignals(t *testing.T) {
+	signals := make(chan os.Signal)
+	stop := make(chan struct{})
+	exited := make(chan int, 1)
+	go stopOnSignals(signals, stop, func(code int) {
+		exited <- code
+	})
+
+	signals <- os.Interrupt
+	<-stop
+	select {
+	case code := <-exited:
+		t.Fatalf("Expected the first signal to shut down gracefully got exit %d", code)
+	default:
+	}
+	signals <- syscall.SIGTERM
+	if code := <-exited; code != 1 {
+		t.Errorf("Expected the second signal to exit with 1 got %d", code)
+	}
+}
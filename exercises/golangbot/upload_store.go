@@ -0,0 +1,409 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errFileNotFound = errors.New("file not found")
+
+/*
+FileStore keeps the upload records of the resumable file uploader. fileHandler only talks to this interface, so the
+uploader can run against Postgres in production and against memoryStore or jsonFileStore on a laptop or in tests.
+UpdateFile only changes the fields of f which are not nil, the same way the original Postgres query did. UpdateFileAt
+does the same, but only if the stored offset is still offset, otherwise it returns errOffsetMismatch. The check and
+the update happen atomically, so of two requests which read the same offset only one can commit its chunk.
+ExpiredFiles returns the fileIDs of the incomplete uploads which were not modified since before. ListFiles returns the
+uploads matching filter ordered by fileID. Usage sums up the uploads of an owner for the quotas. Close releases the
+store once the uploader shut down, it is not used afterwards.
+*/
+type FileStore interface {
+	CreateFile(f file) (string, error)
+	File(fileID string) (file, error)
+	UpdateFile(f file) error
+	UpdateFileAt(f file, offset int) error
+	DeleteFile(fileID string) error
+	ExpiredFiles(before time.Time) ([]string, error)
+	ListFiles(filter fileFilter) ([]file, error)
+	Usage(owner string) (clientUsage, error)
+	Close() error
+}
+
+/*
+fileFilter selects the uploads ListFiles returns. Zero fields select everything, so the zero fileFilter lists all
+uploads. after and limit page through them: only fileIDs greater than after and at most limit of them.
+*/
+type fileFilter struct {
+	complete      *bool
+	createdBefore time.Time
+	filename      string // part of the filename metadata
+	after         int
+	limit         int
+}
+
+func (ff fileFilter) matches(fr fileRecord) bool {
+	if fr.FileID <= ff.after {
+		return false
+	}
+	if ff.complete != nil && fr.UploadComplete != *ff.complete {
+		return false
+	}
+	if !ff.createdBefore.IsZero() && !fr.CreatedAt.Before(ff.createdBefore) {
+		return false
+	}
+	return ff.filename == "" || strings.Contains(fr.UploadMetadata[metadataFileName], ff.filename)
+}
+
+// fileRecord is the stored form of a file. Unlike file it has no pointer fields, so it can be copied and encoded.
+type fileRecord struct {
+	FileID            int               `json:"file_id"`
+	Offset            int               `json:"file_offset"`
+	UploadLength      int               `json:"file_upload_length"`
+	UploadDeferLength bool              `json:"file_upload_defer_length,omitempty"`
+	UploadComplete    bool              `json:"file_upload_complete"`
+	UploadMetadata    map[string]string `json:"file_upload_metadata,omitempty"`
+	UploadConcat      string            `json:"file_upload_concat,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	ModifiedAt        time.Time         `json:"modified_at"`
+	Owner             string            `json:"file_owner,omitempty"`
+}
+
+func (fr fileRecord) file() file {
+	offset := fr.Offset
+	uploadComplete := fr.UploadComplete
+	f := file{
+		fileID:         fr.FileID,
+		offset:         &offset,
+		uploadComplete: &uploadComplete,
+		uploadMetadata: fr.UploadMetadata,
+		uploadConcat:   fr.UploadConcat,
+		createdAt:      fr.CreatedAt,
+		modifiedAt:     fr.ModifiedAt,
+		owner:          fr.Owner,
+	}
+	if !fr.UploadDeferLength {
+		uploadLength := fr.UploadLength
+		f.uploadLength = &uploadLength
+	}
+	return f
+}
+
+func parseFileID(fileID string) (int, error) {
+	fID, err := strconv.Atoi(fileID)
+	if err != nil {
+		return 0, errFileNotFound
+	}
+	return fID, nil
+}
+
+// memoryStore keeps upload records in a map. Everything is lost when the process exits.
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int
+	files  map[int]fileRecord
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{files: make(map[int]fileRecord)}
+}
+
+func (ms *memoryStore) CreateFile(f file) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.createFile(f), nil
+}
+
+func (ms *memoryStore) createFile(f file) string {
+	ms.nextID++
+	now := time.Now()
+	fr := fileRecord{
+		FileID:            ms.nextID,
+		UploadDeferLength: f.uploadLength == nil,
+		UploadMetadata:    f.uploadMetadata,
+		UploadConcat:      f.uploadConcat,
+		CreatedAt:         now,
+		ModifiedAt:        now,
+		Owner:             f.owner,
+	}
+	if f.uploadLength != nil {
+		fr.UploadLength = *f.uploadLength
+	}
+	if f.offset != nil {
+		fr.Offset = *f.offset
+	}
+	if f.uploadComplete != nil {
+		fr.UploadComplete = *f.uploadComplete
+	}
+	ms.files[fr.FileID] = fr
+	return strconv.Itoa(fr.FileID)
+}
+
+func (ms *memoryStore) File(fileID string) (file, error) {
+	fID, err := parseFileID(fileID)
+	if err != nil {
+		return file{}, err
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	fr, ok := ms.files[fID]
+	if !ok {
+		return file{}, errFileNotFound
+	}
+	return fr.file(), nil
+}
+
+func (ms *memoryStore) UpdateFile(f file) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.updateFile(f)
+}
+
+func (ms *memoryStore) UpdateFileAt(f file, offset int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if err := ms.checkOffset(f.fileID, offset); err != nil {
+		return err
+	}
+	return ms.updateFile(f)
+}
+
+func (ms *memoryStore) checkOffset(fileID, offset int) error {
+	fr, ok := ms.files[fileID]
+	if !ok {
+		return errFileNotFound
+	}
+	if fr.Offset != offset {
+		return errOffsetMismatch
+	}
+	return nil
+}
+
+func (ms *memoryStore) updateFile(f file) error {
+	fr, ok := ms.files[f.fileID]
+	if !ok {
+		return errFileNotFound
+	}
+	if f.offset == nil && f.uploadLength == nil && f.uploadComplete == nil {
+		return nil
+	}
+	if f.offset != nil {
+		fr.Offset = *f.offset
+	}
+	if f.uploadLength != nil {
+		fr.UploadLength = *f.uploadLength
+		fr.UploadDeferLength = false
+	}
+	if f.uploadComplete != nil {
+		fr.UploadComplete = *f.uploadComplete
+	}
+	fr.ModifiedAt = time.Now()
+	ms.files[f.fileID] = fr
+	return nil
+}
+
+func (ms *memoryStore) DeleteFile(fileID string) error {
+	fID, err := parseFileID(fileID)
+	if err != nil {
+		return err
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if _, ok := ms.files[fID]; !ok {
+		return errFileNotFound
+	}
+	delete(ms.files, fID)
+	return nil
+}
+
+func (ms *memoryStore) ExpiredFiles(before time.Time) ([]string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var ids []int
+	for _, fr := range ms.files {
+		if !fr.UploadComplete && fr.ModifiedAt.Before(before) {
+			ids = append(ids, fr.FileID)
+		}
+	}
+	sort.Ints(ids)
+	fileIDs := make([]string, len(ids))
+	for i, id := range ids {
+		fileIDs[i] = strconv.Itoa(id)
+	}
+	return fileIDs, nil
+}
+
+func (ms *memoryStore) ListFiles(filter fileFilter) ([]file, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var ids []int
+	for id, fr := range ms.files {
+		if filter.matches(fr) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if filter.limit > 0 && len(ids) > filter.limit {
+		ids = ids[:filter.limit]
+	}
+	files := make([]file, len(ids))
+	for i, id := range ids {
+		files[i] = ms.files[id].file()
+	}
+	return files, nil
+}
+
+func (ms *memoryStore) Usage(owner string) (clientUsage, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var u clientUsage
+	for _, fr := range ms.files {
+		if fr.Owner != owner {
+			continue
+		}
+		if !fr.UploadComplete {
+			u.uploads++
+		}
+		if fr.UploadDeferLength {
+			u.bytes += int64(fr.Offset)
+		} else {
+			u.bytes += int64(fr.UploadLength)
+		}
+	}
+	return u, nil
+}
+
+// Close has nothing to release, the records are gone with the process. jsonFileStore saved them with every change.
+func (ms *memoryStore) Close() error {
+	return nil
+}
+
+/*
+jsonFileStore is a memoryStore which writes all of its records to a JSON file after every change and loads them back
+when it is opened, so uploads survive a restart without needing a database server.
+*/
+type jsonFileStore struct {
+	*memoryStore
+	path string
+}
+
+type jsonFileStoreState struct {
+	NextID int          `json:"next_id"`
+	Files  []fileRecord `json:"files"`
+}
+
+func openJSONFileStore(path string) (*jsonFileStore, error) {
+	js := &jsonFileStore{memoryStore: newMemoryStore(), path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return js, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state jsonFileStoreState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	js.nextID = state.NextID
+	for _, fr := range state.Files {
+		js.files[fr.FileID] = fr
+	}
+	return js, nil
+}
+
+func (js *jsonFileStore) CreateFile(f file) (string, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	fileID := js.createFile(f)
+	if err := js.save(); err != nil {
+		id, _ := strconv.Atoi(fileID)
+		delete(js.files, id)
+		return "", err
+	}
+	return fileID, nil
+}
+
+func (js *jsonFileStore) UpdateFile(f file) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	return js.updateAndSave(f)
+}
+
+func (js *jsonFileStore) UpdateFileAt(f file, offset int) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if err := js.checkOffset(f.fileID, offset); err != nil {
+		return err
+	}
+	return js.updateAndSave(f)
+}
+
+func (js *jsonFileStore) updateAndSave(f file) error {
+	old, ok := js.files[f.fileID]
+	if err := js.updateFile(f); err != nil {
+		return err
+	}
+	if err := js.save(); err != nil {
+		if ok {
+			js.files[f.fileID] = old
+		}
+		return err
+	}
+	return nil
+}
+
+func (js *jsonFileStore) DeleteFile(fileID string) error {
+	fID, err := parseFileID(fileID)
+	if err != nil {
+		return err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	old, ok := js.files[fID]
+	if !ok {
+		return errFileNotFound
+	}
+	delete(js.files, fID)
+	if err := js.save(); err != nil {
+		js.files[fID] = old
+		return err
+	}
+	return nil
+}
+
+// save writes the records to a temporary file first and renames it, so a crash never leaves a half written store.
+func (js *jsonFileStore) save() error {
+	state := jsonFileStoreState{NextID: js.nextID}
+	for _, fr := range js.files {
+		state.Files = append(state.Files, fr)
+	}
+	sort.Slice(state.Files, func(i, j int) bool {
+		return state.Files[i].FileID < state.Files[j].FileID
+	})
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(js.path), filepath.Base(js.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), js.path)
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresStore keeps upload records in the file table of a Postgres database.
+type postgresStore struct {
+	db *sql.DB
+}
+
+func openPostgresStore(connStr string) (*postgresStore, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Println("Connection established successfully")
+	ps := &postgresStore{db: db}
+	err = ps.createTable()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Println("table created successfully")
+	return ps, nil
+}
+
+/*
+createTable creates the file table and brings tables created by older versions up to date: file_upload_length is NULL
+while the length of an upload is deferred, file_upload_metadata holds the Upload-Metadata of an upload as JSON,
+file_upload_concat its Upload-Concat and file_owner the client it counts against. created_at and modified_at are
+TIMESTAMPTZ, older tables stored them without a time zone, in the time zone of the session, which made them shift
+against the time.Time values they are compared with whenever that wasn't UTC. Converting them assumes the session
+time zone hasn't changed since.
+*/
+func (ps *postgresStore) createTable() error {
+	q := `CREATE TABLE IF NOT EXISTS file(file_id SERIAL PRIMARY KEY,
+ 		  file_offset INT NOT NULL, file_upload_length INT, file_upload_complete BOOLEAN NOT NULL,
+ 		  file_upload_metadata TEXT NOT NULL DEFAULT '{}', file_upload_concat TEXT NOT NULL DEFAULT '',
+ 		  file_owner TEXT NOT NULL DEFAULT '',
+          created_at TIMESTAMPTZ default NOW() NOT NULL, modified_at TIMESTAMPTZ default NOW() NOT NULL)`
+	_, err := ps.db.Exec(q)
+	if err != nil {
+		return err
+	}
+	_, err = ps.db.Exec(`ALTER TABLE file ALTER COLUMN file_upload_length DROP NOT NULL,
+		ADD COLUMN IF NOT EXISTS file_upload_metadata TEXT NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS file_upload_concat TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS file_owner TEXT NOT NULL DEFAULT ''`)
+	if err != nil {
+		return err
+	}
+	_, err = ps.db.Exec(`DO $$ BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema()
+				   AND table_name = 'file' AND column_name = 'created_at'
+				   AND data_type = 'timestamp without time zone') THEN
+			ALTER TABLE file ALTER COLUMN created_at TYPE TIMESTAMPTZ, ALTER COLUMN modified_at TYPE TIMESTAMPTZ;
+		END IF;
+	END $$`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ps *postgresStore) CreateFile(f file) (string, error) {
+	metadata, err := json.Marshal(f.uploadMetadata)
+	if err != nil {
+		return "", err
+	}
+	cfstmt := `INSERT INTO file(file_offset, file_upload_length, file_upload_complete, file_upload_metadata,
+			   file_upload_concat, file_owner) VALUES($1, $2, $3, $4, $5, $6) RETURNING file_id`
+	fileID := 0
+	err = ps.db.QueryRow(cfstmt, f.offset, f.uploadLength, f.uploadComplete, string(metadata),
+		f.uploadConcat, f.owner).Scan(&fileID)
+	if err != nil {
+		return "", err
+	}
+	fid := strconv.Itoa(fileID)
+	return fid, nil
+}
+
+func (ps *postgresStore) UpdateFile(f file) error {
+	return ps.updateFile(f, nil)
+}
+
+func (ps *postgresStore) UpdateFileAt(f file, offset int) error {
+	return ps.updateFile(f, &offset)
+}
+
+/*
+updateFile runs the UPDATE of UpdateFile and UpdateFileAt. With an expected offset the WHERE clause checks it too, so
+Postgres decides which of two concurrent updates wins even when they come from different uploader instances.
+*/
+func (ps *postgresStore) updateFile(f file, offset *int) error {
+	var query []string
+	var param []interface{}
+	if f.offset != nil {
+		param = append(param, f.offset)
+		query = append(query, fmt.Sprintf("file_offset = $%d", len(param)))
+	}
+	if f.uploadLength != nil {
+		param = append(param, f.uploadLength)
+		query = append(query, fmt.Sprintf("file_upload_length = $%d", len(param)))
+	}
+	if f.uploadComplete != nil {
+		param = append(param, f.uploadComplete)
+		query = append(query, fmt.Sprintf("file_upload_complete = $%d", len(param)))
+	}
+
+	if len(query) > 0 {
+		query = append(query, "modified_at = NOW()")
+
+		qj := strings.Join(query, ",")
+
+		param = append(param, f.fileID)
+		sqlq := fmt.Sprintf("UPDATE file SET %s WHERE file_id = $%d", qj, len(param))
+		if offset != nil {
+			param = append(param, *offset)
+			sqlq += fmt.Sprintf(" AND file_offset = $%d", len(param))
+		}
+
+		res, err := ps.db.Exec(sqlq, param...)
+		if err != nil {
+			log.Println("Error during file update", err)
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 && offset != nil {
+			return ps.offsetMismatch(f.fileID)
+		}
+		if n == 0 {
+			return errFileNotFound
+		}
+	}
+	return nil
+}
+
+// offsetMismatch tells apart why a conditional update changed no row: the file is gone or its offset moved on.
+func (ps *postgresStore) offsetMismatch(fileID int) error {
+	var exists bool
+	err := ps.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM file WHERE file_id = $1)`, fileID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errFileNotFound
+	}
+	return errOffsetMismatch
+}
+
+func (ps *postgresStore) File(fileID string) (file, error) {
+	fID, err := strconv.Atoi(fileID)
+	if err != nil {
+		log.Println("Unable to convert fileID to string", err)
+		return file{}, errFileNotFound
+	}
+	row := ps.db.QueryRow(`SELECT `+fileColumns+` FROM file WHERE file_id = $1`, fID)
+	f, err := scanFile(row)
+	if err == sql.ErrNoRows {
+		return file{}, errFileNotFound
+	}
+	if err != nil {
+		log.Println("error while fetching file", err)
+		return file{}, err
+	}
+	return f, nil
+}
+
+// fileColumns are the columns scanFile reads, in its order.
+const fileColumns = `file_id, file_offset, file_upload_length, file_upload_complete, file_upload_metadata,
+					 file_upload_concat, created_at, modified_at, file_owner`
+
+// scanFile reads a file from a row of fileColumns, row is a *sql.Row or *sql.Rows.
+func scanFile(row interface {
+	Scan(dest ...interface{}) error
+}) (file, error) {
+	f := file{}
+	var metadata string
+	err := row.Scan(&f.fileID, &f.offset, &f.uploadLength, &f.uploadComplete, &metadata, &f.uploadConcat,
+		&f.createdAt, &f.modifiedAt, &f.owner)
+	if err != nil {
+		return file{}, err
+	}
+	err = json.Unmarshal([]byte(metadata), &f.uploadMetadata)
+	if err != nil {
+		return file{}, err
+	}
+	return f, nil
+}
+
+/*
+ListFiles builds the WHERE clause from the fields of filter which are set. The filename is looked up in the JSON of
+file_upload_metadata.
+*/
+func (ps *postgresStore) ListFiles(filter fileFilter) ([]file, error) {
+	where := []string{"file_id > $1"}
+	param := []interface{}{filter.after}
+	if filter.complete != nil {
+		param = append(param, *filter.complete)
+		where = append(where, fmt.Sprintf("file_upload_complete = $%d", len(param)))
+	}
+	if !filter.createdBefore.IsZero() {
+		param = append(param, filter.createdBefore)
+		where = append(where, fmt.Sprintf("created_at < $%d", len(param)))
+	}
+	if filter.filename != "" {
+		param = append(param, filter.filename)
+		where = append(where, fmt.Sprintf("strpos(file_upload_metadata::jsonb ->> '%s', $%d) > 0", metadataFileName,
+			len(param)))
+	}
+	q := fmt.Sprintf("SELECT %s FROM file WHERE %s ORDER BY file_id", fileColumns, strings.Join(where, " AND "))
+	if filter.limit > 0 {
+		param = append(param, filter.limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(param))
+	}
+	rows, err := ps.db.Query(q, param...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var files []file
+	for rows.Next() {
+		f, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+func (ps *postgresStore) DeleteFile(fileID string) error {
+	fID, err := strconv.Atoi(fileID)
+	if err != nil {
+		return errFileNotFound
+	}
+	res, err := ps.db.Exec(`DELETE FROM file WHERE file_id = $1`, fID)
+	if err != nil {
+		log.Println("Error during file delete", err)
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errFileNotFound
+	}
+	return nil
+}
+
+func (ps *postgresStore) ExpiredFiles(before time.Time) ([]string, error) {
+	rows, err := ps.db.Query(`SELECT file_id FROM file WHERE NOT file_upload_complete AND modified_at < $1
+							  ORDER BY file_id`, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fileIDs []string
+	for rows.Next() {
+		var fileID int
+		if err := rows.Scan(&fileID); err != nil {
+			return nil, err
+		}
+		fileIDs = append(fileIDs, strconv.Itoa(fileID))
+	}
+	return fileIDs, rows.Err()
+}
+
+func (ps *postgresStore) Usage(owner string) (clientUsage, error) {
+	var u clientUsage
+	err := ps.db.QueryRow(`SELECT COUNT(*) FILTER (WHERE NOT file_upload_complete),
+						   COALESCE(SUM(COALESCE(file_upload_length, file_offset)), 0) FROM file WHERE file_owner = $1`,
+		owner).Scan(&u.uploads, &u.bytes)
+	return u, err
+}
+
+func (ps *postgresStore) Close() error {
+	return ps.db.Close()
+}
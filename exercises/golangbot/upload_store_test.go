@@ -0,0 +1,248 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFileStore(t *testing.T, store FileStore) {
+	offset := 0
+	uploadComplete := false
+	uploadLength := 250
+	metadata := map[string]string{"filename": "world_domination_plan.pdf"}
+	owner := "ip:192.0.2.1"
+	fileID, err := store.CreateFile(file{offset: &offset, uploadLength: &uploadLength, uploadComplete: &uploadComplete,
+		uploadMetadata: metadata, owner: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := store.File(fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 0 || f.uploadLength == nil || *f.uploadLength != 250 || *f.uploadComplete {
+		t.Errorf("For new file expected offset 0, length 250, incomplete got offset %d, length %v, complete %t",
+			*f.offset, f.uploadLength, *f.uploadComplete)
+	}
+	if !reflect.DeepEqual(f.uploadMetadata, metadata) {
+		t.Errorf("For new file expected metadata %v got %v", metadata, f.uploadMetadata)
+	}
+	if f.owner != owner {
+		t.Errorf("For new file expected owner %s got %s", owner, f.owner)
+	}
+
+	newOffset := 100
+	err = store.UpdateFile(file{fileID: f.fileID, offset: &newOffset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = store.File(fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 100 || *f.uploadComplete {
+		t.Errorf("After offset update expected offset 100, incomplete got offset %d, complete %t",
+			*f.offset, *f.uploadComplete)
+	}
+
+	uploadComplete = true
+	err = store.UpdateFile(file{fileID: f.fileID, uploadComplete: &uploadComplete})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = store.File(fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 100 || !*f.uploadComplete {
+		t.Errorf("After completion update expected offset 100, complete got offset %d, complete %t",
+			*f.offset, *f.uploadComplete)
+	}
+
+	if _, err := store.File("12345"); err != errFileNotFound {
+		t.Errorf("For unknown file expected %v got %v", errFileNotFound, err)
+	}
+	if err := store.UpdateFile(file{fileID: 12345, offset: &newOffset}); err != errFileNotFound {
+		t.Errorf("For update of unknown file expected %v got %v", errFileNotFound, err)
+	}
+
+	staleOffset := 0
+	err = store.UpdateFileAt(file{fileID: f.fileID, offset: &newOffset}, staleOffset)
+	if err != errOffsetMismatch {
+		t.Errorf("For update at stale offset expected %v got %v", errOffsetMismatch, err)
+	}
+	nextOffset := 150
+	err = store.UpdateFileAt(file{fileID: f.fileID, offset: &nextOffset}, newOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = store.File(fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 150 {
+		t.Errorf("After update at current offset expected offset 150 got %d", *f.offset)
+	}
+	err = store.UpdateFileAt(file{fileID: 12345, offset: &nextOffset}, newOffset)
+	if err != errFileNotFound {
+		t.Errorf("For update at offset of unknown file expected %v got %v", errFileNotFound, err)
+	}
+
+	incomplete := false
+	deferred, err := store.CreateFile(file{offset: &offset, uploadComplete: &incomplete, owner: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = store.File(deferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.uploadLength != nil {
+		t.Errorf("For deferred length expected no length got %d", *f.uploadLength)
+	}
+	err = store.UpdateFile(file{fileID: f.fileID, uploadLength: &uploadLength})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = store.File(deferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.uploadLength == nil || *f.uploadLength != 250 {
+		t.Errorf("After length update expected length 250 got %v", f.uploadLength)
+	}
+
+	expired, err := store.ExpiredFiles(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expired, []string{deferred}) {
+		t.Errorf("For expired files expected only the incomplete %v got %v", []string{deferred}, expired)
+	}
+	expired, err = store.ExpiredFiles(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 0 {
+		t.Errorf("For files expired a minute ago expected none got %v", expired)
+	}
+
+	usage, err := store.Usage(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != (clientUsage{uploads: 1, bytes: 500}) {
+		t.Errorf("For usage expected 1 incomplete upload and 500 bytes got %+v", usage)
+	}
+	usage, err = store.Usage("user:nobody")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != (clientUsage{}) {
+		t.Errorf("For usage of a client without uploads expected nothing got %+v", usage)
+	}
+
+	complete := true
+	lists := []struct {
+		name     string
+		filter   fileFilter
+		expected []string
+	}{
+		{"all files", fileFilter{}, []string{fileID, deferred}},
+		{"complete files", fileFilter{complete: &complete}, []string{fileID}},
+		{"incomplete files", fileFilter{complete: &incomplete}, []string{deferred}},
+		{"files after the first", fileFilter{after: f.fileID - 1}, []string{deferred}},
+		{"first file", fileFilter{limit: 1}, []string{fileID}},
+		{"filename", fileFilter{filename: "domination"}, []string{fileID}},
+		{"unknown filename", fileFilter{filename: "peace"}, []string{}},
+		{"created before", fileFilter{createdBefore: time.Now().Add(-time.Minute)}, []string{}},
+	}
+	for _, list := range lists {
+		files, err := store.ListFiles(list.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, f := range files {
+			ids = append(ids, strconv.Itoa(f.fileID))
+		}
+		if !reflect.DeepEqual(ids, list.expected) {
+			t.Errorf("For listing %s expected %v got %v", list.name, list.expected, ids)
+		}
+	}
+
+	deleted, err := store.CreateFile(file{uploadLength: &uploadLength})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteFile(deleted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.File(deleted); err != errFileNotFound {
+		t.Errorf("For deleted file expected %v got %v", errFileNotFound, err)
+	}
+	if err := store.DeleteFile(deleted); err != errFileNotFound {
+		t.Errorf("For delete of unknown file expected %v got %v", errFileNotFound, err)
+	}
+}
+
+func TestOpenFileStore(t *testing.T) {
+	_, err := openFileStore(storePostgres, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "-database-dsn") {
+		t.Errorf("For postgres store without DSN expected error asking for -database-dsn got %v", err)
+	}
+	_, err = openFileStore("redis", "", "", "")
+	if err == nil {
+		t.Error("For unknown store expected error")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testFileStore(t, newMemoryStore())
+}
+
+func TestJSONFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storePath := filepath.Join(dir, "uploads.json")
+
+	store, err := openJSONFileStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFileStore(t, store)
+
+	reopened, err := openJSONFileStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := reopened.File("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.offset != 150 || !*f.uploadComplete {
+		t.Errorf("After reopening expected offset 150, complete got offset %d, complete %t",
+			*f.offset, *f.uploadComplete)
+	}
+	if _, err := reopened.File("3"); err != errFileNotFound {
+		t.Errorf("After reopening expected deleted file to stay deleted got %v", err)
+	}
+	uploadLength := 10
+	fileID, err := reopened.CreateFile(file{uploadLength: &uploadLength})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileID != "4" {
+		t.Errorf("After reopening expected next fileID 4 got %s", fileID)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// envPrefix starts the environment variables of the uploader, UPLOADER_MAX_SIZE sets -max-size.
+const envPrefix = "UPLOADER_"
+
+/*
+uploaderConfig is how the uploader is set up. Every field is a flag and the same name, upper case with underscores and
+prefixed with UPLOADER_, is its environment variable. The JSON file named by -config or UPLOADER_CONFIG uses the flag
+names as keys, for example {"listen": ":9090", "max-size": 1048576}. Flags win over the environment, which wins over
+the file.
+*/
+type uploaderConfig struct {
+	configFile      string
+	listen          string
+	baseURL         string // scheme, host and path prefix of the upload URLs, derived from the request if empty
+	storeType       string
+	storePath       string
+	databaseDSN     string
+	maxSize         int
+	maxUploads      int
+	maxBytes        int64
+	identityHeader  string
+	expiration      time.Duration
+	janitorInterval time.Duration
+	blobType        string
+	blobDir         string
+	s3Endpoint      string
+	s3Bucket        string
+	s3Region        string
+	s3PartSize      int64
+	hookCommand     string
+	hookURL         string
+	shutdownTimeout time.Duration
+	adminToken      string
+}
+
+func newConfigFlagSet(cfg *uploaderConfig) *flag.FlagSet {
+	fs := flag.NewFlagSet("uploader", flag.ContinueOnError)
+	fs.StringVar(&cfg.configFile, "config", "", "JSON file with settings, keyed by flag name")
+	fs.StringVar(&cfg.listen, "listen", ":8080", "address the server listens on")
+	fs.StringVar(&cfg.baseURL, "base-url", "", "URL the uploads are reachable at, e.g. https://example.com, taken from the request if empty")
+	fs.StringVar(&cfg.storeType, "store", storeFile, "where upload records are kept: memory, file or postgres")
+	fs.StringVar(&cfg.storePath, "store-path", "", "path of the JSON file used by the file store")
+	fs.StringVar(&cfg.databaseDSN, "database-dsn", "", "connection string of the postgres store, required with -store postgres")
+	fs.IntVar(&cfg.maxSize, "max-size", 0, "largest upload in bytes the server accepts, 0 for no limit")
+	fs.IntVar(&cfg.maxUploads, "max-uploads-per-client", 0, "incomplete uploads a client may have at a time, 0 for no limit")
+	fs.Int64Var(&cfg.maxBytes, "max-bytes-per-client", 0, "bytes all uploads of a client may hold together, 0 for no limit")
+	fs.StringVar(&cfg.identityHeader, "identity-header", "", "header an authenticating proxy puts the client identity in, the client IP is used if empty")
+	fs.DurationVar(&cfg.expiration, "expiration", 0, "how long incomplete uploads are kept after their last change, 0 for forever")
+	fs.DurationVar(&cfg.janitorInterval, "janitor-interval", time.Hour, "how often expired uploads are deleted")
+	fs.StringVar(&cfg.blobType, "blob", blobLocal, "where uploaded bytes are kept: local or s3")
+	fs.StringVar(&cfg.blobDir, "blob-dir", "", "directory of the local blob store, ~/fileserver if empty")
+	fs.StringVar(&cfg.s3Endpoint, "s3-endpoint", "https://s3.amazonaws.com", "scheme and host of the S3 compatible store")
+	fs.StringVar(&cfg.s3Bucket, "s3-bucket", "", "bucket the s3 blob store keeps uploads in")
+	fs.StringVar(&cfg.s3Region, "s3-region", "us-east-1", "region of the S3 bucket")
+	fs.Int64Var(&cfg.s3PartSize, "s3-part-size", minS3PartSize, "size of the parts uploads are assembled from")
+	fs.StringVar(&cfg.hookCommand, "hook-command", "", "command run at every hook point with the hook type as argument")
+	fs.StringVar(&cfg.hookURL, "hook-url", "", "URL every hook event is posted to")
+	fs.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 30*time.Second, "how long requests in flight may take when the server shuts down")
+	fs.StringVar(&cfg.adminToken, "admin-token", "", "bearer token of the admin API, which is off if empty")
+	return fs
+}
+
+// envName returns the environment variable of the flag name.
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+/*
+loadConfig reads the configuration from the command line args, the environment as returned by getenv and the config
+file. The command line is parsed first, so it can name the config file, and only the settings it leaves out are taken
+from the environment or the file.
+*/
+func loadConfig(args []string, getenv func(string) string) (uploaderConfig, error) {
+	var cfg uploaderConfig
+	fs := newConfigFlagSet(&cfg)
+	err := fs.Parse(args)
+	if err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	onCommandLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		onCommandLine[f.Name] = true
+	})
+
+	env := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if v := getenv(envName(f.Name)); v != "" && !onCommandLine[f.Name] {
+			env[f.Name] = v
+		}
+	})
+	if v, ok := env["config"]; ok {
+		cfg.configFile = v
+	}
+	if cfg.configFile != "" {
+		settings, err := readConfigFile(cfg.configFile)
+		if err != nil {
+			return cfg, err
+		}
+		for name, v := range settings {
+			if fs.Lookup(name) == nil || name == "config" {
+				return cfg, fmt.Errorf("unknown setting %q in %s", name, cfg.configFile)
+			}
+			if onCommandLine[name] {
+				continue
+			}
+			err = fs.Set(name, v)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid value %q for %s in %s: %v", v, name, cfg.configFile, err)
+			}
+		}
+	}
+	for name, v := range env {
+		err = fs.Set(name, v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid value %q for %s: %v", v, envName(name), err)
+		}
+	}
+	return cfg, nil
+}
+
+// readConfigFile returns the settings of a config file as the strings they would be given as flags.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	err = d.Decode(&values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	settings := make(map[string]string, len(values))
+	for name, v := range values {
+		switch v.(type) {
+		case string, json.Number, bool:
+			settings[name] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("setting %q in %s is no string, number or bool", name, path)
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	path := filepath.Join(dir, "uploader.json")
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func envOf(env map[string]string) func(string) string {
+	return func(name string) string {
+		return env[name]
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig(nil, envOf(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.listen != ":8080" || cfg.baseURL != "" || cfg.storeType != storeFile ||
+		cfg.databaseDSN != "" || cfg.janitorInterval != time.Hour || cfg.s3PartSize != minS3PartSize {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"listen": ":9000",
+		"base-url": "https://file.example.com",
+		"store": "file",
+		"store-path": "/var/lib/uploader/uploads.json",
+		"max-size": 1048576,
+		"expiration": "24h"
+	}`)
+	env := map[string]string{
+		"UPLOADER_CONFIG":       path,
+		"UPLOADER_STORE":        "memory",
+		"UPLOADER_MAX_SIZE":     "2048",
+		"UPLOADER_DATABASE_DSN": "postgres://uploader@db/uploads",
+	}
+	cfg, err := loadConfig([]string{"-max-size", "4096", "-blob-dir", "/srv/blobs"}, envOf(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, test := range map[string]struct {
+		got, want interface{}
+	}{
+		"listen from the file":           {cfg.listen, ":9000"},
+		"base URL from the file":         {cfg.baseURL, "https://file.example.com"},
+		"store path from the file":       {cfg.storePath, "/var/lib/uploader/uploads.json"},
+		"expiration from the file":       {cfg.expiration, 24 * time.Hour},
+		"store from the environment":     {cfg.storeType, storeMemory},
+		"DSN from the environment":       {cfg.databaseDSN, "postgres://uploader@db/uploads"},
+		"max size from the command line": {cfg.maxSize, 4096},
+		"blob dir from the command line": {cfg.blobDir, "/srv/blobs"},
+		"default blob store":             {cfg.blobType, blobLocal},
+	} {
+		if test.got != test.want {
+			t.Errorf("Expected %s to be %v got %v", name, test.want, test.got)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string
+		err  string
+	}{
+		{name: "unknown flag", args: []string{"-port", "80"}, err: "flag provided but not defined"},
+		{name: "extra argument", args: []string{"serve"}, err: "unexpected arguments"},
+		{name: "invalid environment variable", env: map[string]string{"UPLOADER_MAX_SIZE": "big"},
+			err: "UPLOADER_MAX_SIZE"},
+		{name: "unknown setting", file: `{"port": 80}`, err: `unknown setting "port"`},
+		{name: "invalid setting", file: `{"expiration": 24}`, err: "invalid value"},
+		{name: "nested setting", file: `{"listen": {"port": 80}}`, err: "no string, number or bool"},
+		{name: "broken file", file: `{"listen": `, err: "invalid config file"},
+	}
+	for _, test := range tests {
+		args := test.args
+		if test.file != "" {
+			args = append(args, "-config", writeConfigFile(t, test.file))
+		}
+		_, err := loadConfig(args, envOf(test.env))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("For %s expected error containing %q got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -8,11 +8,10 @@ import (
 )
 
 /*
-The function above generates a random number and writes it to the channel data and then calls Done on the waitgroup to
-notify that it is done with its task.
+The function above writes the random number n to the channel data and then calls Done on the waitgroup to notify that
+it is done with its task.
  */
-func produce(data chan int, wg *sync.WaitGroup) {
-	n := rand.Intn(999)
+func produce(n int, data chan int, wg *sync.WaitGroup) {
 	data <- n
 	wg.Done()
 }
@@ -46,7 +45,11 @@ func consume(data chan int, done chan bool) {
 	done <- true
 }
 
-func writeFiles() {
+/*
+writeFiles takes the random numbers it writes concurrently from rnd, so a caller passing a seeded source always gets the
+same numbers in the file, though not in the same order.
+ */
+func writeFiles(rnd *rand.Rand) {
 	fmt.Printf("\nBeginning of writing string to a file...\n")
 	/*
 	One of the most common file writing operation is writing string to a file. This is quite simple to do. It consists
@@ -159,7 +162,8 @@ func writeFiles() {
 	wg := sync.WaitGroup{}
 	for i := 0; i < 100; i++ {
 		wg.Add(1)
-		go produce(data, &wg)
+		// rnd is not safe for concurrent use, so the numbers are drawn here rather than in the goroutines.
+		go produce(rnd.Intn(999), data, &wg)
 	}
 	go consume(data, done)
 	/*
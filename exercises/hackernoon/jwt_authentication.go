@@ -1,42 +1,22 @@
 package main
 
 import (
-	"context"
-	"fmt"
-	"github.com/dgrijalva/jwt-go"
+	"flag"
+	"golang-tutorial/exercises/jwtauth"
 	"log"
 	"net/http"
-	"strings"
+	"os"
 )
 
-func middleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := strings.Split(r.Header.Get("Authorization"), "Bearer ")
-		if len(authHeader) != 2 {
-			fmt.Println("Malformed token")
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("Malformed Token"))
-		} else {
-			jwtToken := authHeader[1]
-			token, err := jwt.Parse(jwtToken, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte("asdasfas"), nil
-			})
+// secret is the HMAC secret of the tokens. It has no default, a secret in the source code is no secret.
+var secret = flag.String("secret", os.Getenv("JWT_SECRET"), "secret the tokens are signed with, JWT_SECRET by default")
 
-			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-				ctx := context.WithValue(r.Context(), "props", claims)
-				// Access context values in handlers like this
-				// props, _ := r.Context().Value("props").(jwt.MapClaims)
-				next.ServeHTTP(w, r.WithContext(ctx))
-			} else {
-				fmt.Println(err)
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte("Unauthorized"))
-			}
-		}
-	})
+// middleware lets only requests with a bearer token signed with the secret through to next.
+func middleware(next http.Handler) http.Handler {
+	auth := jwtauth.Authenticator{Extract: jwtauth.FromBearer(), Keyfunc: jwtauth.HMACKey([]byte(*secret))}
+	// Access the claims in handlers like this
+	// claims, _ := jwtauth.ClaimsFromContext(r.Context())
+	return auth.Middleware(next)
 }
 
 func pong(w http.ResponseWriter, r *http.Request) {
@@ -57,6 +37,9 @@ func runJwtAuthentication() {
 		The scope of this article is limited to creating a middleware in Golang to check the validity of a
 		JWT in an incoming request.
 	*/
+	if *secret == "" {
+		log.Fatal("Set the secret the tokens are signed with in -secret or JWT_SECRET")
+	}
 	http.Handle("/ping", middleware(http.HandlerFunc(pong)))
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
\ No newline at end of file
@@ -1,5 +1,8 @@
 package main
 
+import "flag"
+
 func main() {
+	flag.Parse()
 	runJwtAuthentication()
 }
\ No newline at end of file
@@ -0,0 +1,123 @@
+package jwtauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// CodeForbidden is the error code of requests whose token doesn't meet a Requirement.
+const CodeForbidden = "forbidden"
+
+// Requirement checks the claims of a request, the error tells what they lack.
+type Requirement func(claims jwt.MapClaims) error
+
+/*
+HasRole requires one of roles in the roles claim, which lists the roles of the user as an array of strings or, for a
+single role, a string.
+*/
+func HasRole(roles ...string) Requirement {
+	return func(claims jwt.MapClaims) error {
+		for _, role := range stringsClaim(claims["roles"]) {
+			if contains(roles, role) {
+				return nil
+			}
+		}
+		if len(roles) == 1 {
+			return fmt.Errorf("role %s required", roles[0])
+		}
+		return fmt.Errorf("one of the roles %s required", strings.Join(roles, ", "))
+	}
+}
+
+/*
+HasScope requires all of scopes in the token. Scopes are read from the scope claim, a string of scopes separated by
+spaces as in OAuth 2.0, or from scp, an array of them.
+*/
+func HasScope(scopes ...string) Requirement {
+	return func(claims jwt.MapClaims) error {
+		granted := stringsClaim(claims["scp"])
+		if scope, ok := claims["scope"].(string); ok {
+			granted = append(granted, strings.Fields(scope)...)
+		}
+		var missing []string
+		for _, scope := range scopes {
+			if !contains(granted, scope) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("scope %s required", strings.Join(missing, " "))
+		}
+		return nil
+	}
+}
+
+// AnyOf requires one of requirements to be met.
+func AnyOf(requirements ...Requirement) Requirement {
+	return func(claims jwt.MapClaims) error {
+		var reasons []string
+		for _, req := range requirements {
+			err := req(claims)
+			if err == nil {
+				return nil
+			}
+			reasons = append(reasons, err.Error())
+		}
+		return errors.New(strings.Join(reasons, " or "))
+	}
+}
+
+// stringsClaim returns a claim holding a string or an array of strings as a slice.
+func stringsClaim(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var values []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+/*
+Require returns a middleware passing requests on only if the claims the Authenticator stored in their context meet
+all requirements. Others are answered with 403 Forbidden and the reason, requests which didn't go through an
+Authenticator with 401 Unauthorized. It is a mux.MiddlewareFunc, so it goes after the Authenticator on a gorilla/mux
+router or subrouter,
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Middleware, jwtauth.Require(jwtauth.HasRole("admin")))
+
+and wraps single handlers of an http.ServeMux:
+
+	http.Handle("/admin", auth.Middleware(jwtauth.Require(jwtauth.HasRole("admin"))(adminHandler)))
+*/
+func Require(requirements ...Requirement) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				WriteError(w, http.StatusUnauthorized, CodeMissingToken, "request is not authenticated")
+				return
+			}
+			for _, req := range requirements {
+				if err := req(claims); err != nil {
+					WriteError(w, http.StatusForbidden, CodeForbidden, err.Error())
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/mux"
+)
+
+func TestRequirements(t *testing.T) {
+	claims := jwt.MapClaims{"roles": []interface{}{"editor", "reader"}, "scope": "uploads:read uploads:write"}
+	tests := []struct {
+		name   string
+		req    Requirement
+		claims jwt.MapClaims
+		reason string // the expected error, empty if the requirement is met
+	}{
+		{"role", HasRole("editor"), claims, ""},
+		{"one of the roles", HasRole("admin", "reader"), claims, ""},
+		{"missing role", HasRole("admin"), claims, "role admin required"},
+		{"missing roles", HasRole("admin", "owner"), claims, "one of the roles admin, owner required"},
+		{"single role as string", HasRole("admin"), jwt.MapClaims{"roles": "admin"}, ""},
+		{"no roles", HasRole("admin"), jwt.MapClaims{}, "role admin required"},
+		{"scopes", HasScope("uploads:read", "uploads:write"), claims, ""},
+		{"missing scope", HasScope("uploads:read", "uploads:delete"), claims, "scope uploads:delete required"},
+		{"scp array", HasScope("uploads:read"), jwt.MapClaims{"scp": []interface{}{"uploads:read"}}, ""},
+		{"no scopes", HasScope("uploads:read"), jwt.MapClaims{}, "scope uploads:read required"},
+		{"any of met", AnyOf(HasRole("admin"), HasScope("uploads:read")), claims, ""},
+		{"any of unmet", AnyOf(HasRole("admin"), HasScope("uploads:delete")), claims,
+			"role admin required or scope uploads:delete required"},
+	}
+	for _, test := range tests {
+		err := test.req(test.claims)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		}
+		if reason != test.reason {
+			t.Errorf("For %s expected %q got %q", test.name, test.reason, reason)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	auth := &Authenticator{Keyfunc: HMACKey(secret)}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	r := mux.NewRouter()
+	r.Handle("/public", ok)
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Middleware, Require(HasRole("admin")))
+	admin.Handle("/users", ok)
+	admin.Handle("/uploads", Require(HasScope("uploads:delete"))(ok)).Methods("DELETE")
+
+	sm := http.NewServeMux()
+	sm.Handle("/admin", auth.Middleware(Require(HasRole("admin"))(ok)))
+	// Without an Authenticator in front there are no claims to check.
+	sm.Handle("/unauthenticated", Require(HasRole("admin"))(ok))
+
+	token := func(claims jwt.MapClaims) string {
+		claims["exp"] = inAnHour()
+		return sign(t, jwt.SigningMethodHS256, secret, claims)
+	}
+	adminToken := token(jwt.MapClaims{"roles": []interface{}{"admin"}, "scope": "uploads:delete"})
+	readerToken := token(jwt.MapClaims{"roles": []interface{}{"reader"}})
+	limitedAdmin := token(jwt.MapClaims{"roles": []interface{}{"admin"}})
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		method  string
+		path    string
+		token   string
+		status  int
+		reason  string
+	}{
+		{"public route", r, "GET", "/public", "", http.StatusOK, ""},
+		{"admin", r, "GET", "/admin/users", adminToken, http.StatusOK, ""},
+		{"reader", r, "GET", "/admin/users", readerToken, http.StatusForbidden, "role admin required"},
+		{"no token", r, "GET", "/admin/users", "", http.StatusUnauthorized, ""},
+		{"admin with scope", r, "DELETE", "/admin/uploads", adminToken, http.StatusOK, ""},
+		{"admin without scope", r, "DELETE", "/admin/uploads", limitedAdmin, http.StatusForbidden,
+			"scope uploads:delete required"},
+		{"ServeMux admin", sm, "GET", "/admin", adminToken, http.StatusOK, ""},
+		{"ServeMux reader", sm, "GET", "/admin", readerToken, http.StatusForbidden, "role admin required"},
+		{"no Authenticator", sm, "GET", "/unauthenticated", adminToken, http.StatusUnauthorized, ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		w := httptest.NewRecorder()
+		test.handler.ServeHTTP(w, req)
+		if w.Code != test.status {
+			t.Errorf("For %s expected status %d got %d: %s", test.name, test.status, w.Code, w.Body)
+			continue
+		}
+		if test.status != http.StatusForbidden {
+			continue
+		}
+		var body errorBody
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body.Error != CodeForbidden || !strings.Contains(body.Message, test.reason) {
+			t.Errorf("For %s expected %s with reason %q got %s", test.name, CodeForbidden, test.reason, w.Body)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package jwtauth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Errors returned by extractors.
+var (
+	ErrMissingToken   = errors.New("no token in request")
+	ErrMalformedToken = errors.New("malformed token")
+)
+
+/*
+Extractor finds the token in a request. It returns ErrMissingToken if the request carries none and ErrMalformedToken
+if the place the token belongs to holds something else.
+*/
+type Extractor func(r *http.Request) (string, error)
+
+// FromHeader takes the token from the request header name, as the Token header of the tutorialedge server.
+func FromHeader(name string) Extractor {
+	return func(r *http.Request) (string, error) {
+		token := r.Header.Get(name)
+		if token == "" {
+			return "", ErrMissingToken
+		}
+		return token, nil
+	}
+}
+
+// FromBearer takes the token from an Authorization header of the form "Bearer <token>".
+func FromBearer() Extractor {
+	return func(r *http.Request) (string, error) {
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			return "", ErrMissingToken
+		}
+		// The scheme is case insensitive, see RFC 7235.
+		const prefix = "bearer "
+		if len(auth) <= len(prefix) || strings.ToLower(auth[:len(prefix)]) != prefix {
+			return "", ErrMalformedToken
+		}
+		token := strings.TrimSpace(auth[len(prefix):])
+		if token == "" || strings.Contains(token, " ") {
+			return "", ErrMalformedToken
+		}
+		return token, nil
+	}
+}
+
+// FromCookie takes the token from the cookie name.
+func FromCookie(name string) Extractor {
+	return func(r *http.Request) (string, error) {
+		c, err := r.Cookie(name)
+		if err != nil || c.Value == "" {
+			return "", ErrMissingToken
+		}
+		return c.Value, nil
+	}
+}
+
+/*
+FromQuery takes the token from the query parameter name. Query strings end up in access logs and browser histories,
+so it is meant for the few clients which can't set a header, such as browsers opening a WebSocket.
+*/
+func FromQuery(name string) Extractor {
+	return func(r *http.Request) (string, error) {
+		token := r.URL.Query().Get(name)
+		if token == "" {
+			return "", ErrMissingToken
+		}
+		return token, nil
+	}
+}
+
+/*
+FirstOf tries the extractors in order and returns the token of the first one which finds something. A malformed token
+stops the search, the client clearly meant to send it there.
+*/
+func FirstOf(extractors ...Extractor) Extractor {
+	return func(r *http.Request) (string, error) {
+		for _, extract := range extractors {
+			token, err := extract(r)
+			if err != ErrMissingToken {
+				return token, err
+			}
+		}
+		return "", ErrMissingToken
+	}
+}
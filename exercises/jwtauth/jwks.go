@@ -0,0 +1,390 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWK is a public key in the JSON Web Key format of RFC 7517. Byte values are base64url encoded without padding.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP keys, Y is only set for EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the JSON document served at a JWKS endpoint.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// NewJWK returns the JWK of the public key pub for verifying signatures.
+func NewJWK(kid string, pub crypto.PublicKey) (JWK, error) {
+	method, err := SigningMethod(pub)
+	if err != nil {
+		return JWK{}, err
+	}
+	jwk := JWK{Kid: kid, Use: "sig", Alg: method.Alg()}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64.EncodeToString(k.N.Bytes())
+		jwk.E = b64.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the size of the curve, see RFC 7518 section 6.2.1.2.
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = k.Curve.Params().Name
+		jwk.X = b64.EncodeToString(padLeft(k.X.Bytes(), size))
+		jwk.Y = b64.EncodeToString(padLeft(k.Y.Bytes(), size))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64.EncodeToString(k)
+	}
+	return jwk, nil
+}
+
+func padLeft(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
+
+// minRSABits is the shortest RSA modulus a JWK may have, shorter ones can be factored.
+const minRSABits = 2048
+
+/*
+PublicKey returns the key k describes. RSA keys need a modulus of at least minRSABits and an exponent above 1 which
+fits into 31 bits, as crypto/rsa requires.
+*/
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n of JWK %s: %v", k.Kid, err)
+		}
+		modulus := new(big.Int).SetBytes(n)
+		if modulus.BitLen() < minRSABits {
+			return nil, fmt.Errorf("modulus of JWK %s has %d bits, at least %d are needed", k.Kid, modulus.BitLen(),
+				minRSABits)
+		}
+		e, err := b64.DecodeString(k.E)
+		exponent := new(big.Int).SetBytes(e)
+		if err != nil || exponent.BitLen() > 31 || exponent.Int64() <= 1 {
+			return nil, fmt.Errorf("invalid e of JWK %s", k.Kid)
+		}
+		return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q of JWK %s", k.Crv, k.Kid)
+		}
+		x, errX := b64.DecodeString(k.X)
+		y, errY := b64.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid coordinates of JWK %s", k.Kid)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("point of JWK %s is not on %s", k.Kid, k.Crv)
+		}
+		return pub, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q of JWK %s", k.Crv, k.Kid)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid x of JWK %s", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q of JWK %s", k.Kty, k.Kid)
+}
+
+// errUnknownKey is returned by KeySet.Keyfunc for a kid it doesn't hold.
+var errUnknownKey = errors.New("unknown signing key")
+
+type keySetEntry struct {
+	jwk JWK
+	pub crypto.PublicKey
+}
+
+/*
+KeySet holds the public keys tokens are signed with, by kid. An issuer serves it as its JWKS endpoint and rotates keys
+by adding the new key, signing with it, and removing the old one once the tokens signed with it have expired.
+The zero value is an empty set ready to use.
+*/
+type KeySet struct {
+	mu   sync.RWMutex
+	kids []string
+	keys map[string]keySetEntry
+}
+
+// Add adds pub to the set under kid, replacing the key kid had before. A private key adds its public key.
+func (ks *KeySet) Add(kid string, pub crypto.PublicKey) error {
+	if signer, ok := pub.(crypto.Signer); ok {
+		pub = signer.Public()
+	}
+	jwk, err := NewJWK(kid, pub)
+	if err != nil {
+		return err
+	}
+	return ks.add(jwk, pub)
+}
+
+func (ks *KeySet) add(jwk JWK, pub crypto.PublicKey) error {
+	if jwk.Kid == "" {
+		return fmt.Errorf("key without kid")
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.keys == nil {
+		ks.keys = make(map[string]keySetEntry)
+	}
+	if _, ok := ks.keys[jwk.Kid]; !ok {
+		ks.kids = append(ks.kids, jwk.Kid)
+	}
+	ks.keys[jwk.Kid] = keySetEntry{jwk: jwk, pub: pub}
+	return nil
+}
+
+// Remove removes the key kid from the set.
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if _, ok := ks.keys[kid]; !ok {
+		return
+	}
+	delete(ks.keys, kid)
+	for i, k := range ks.kids {
+		if k == kid {
+			ks.kids = append(ks.kids[:i], ks.kids[i+1:]...)
+			break
+		}
+	}
+}
+
+// JWKS returns the keys of the set in the order they were added.
+func (ks *KeySet) JWKS() JWKSet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	set := JWKSet{Keys: []JWK{}}
+	for _, kid := range ks.kids {
+		set.Keys = append(set.Keys, ks.keys[kid].jwk)
+	}
+	return set
+}
+
+// ServeHTTP serves the set as JWKS endpoint, usually mounted at /.well-known/jwks.json.
+func (ks *KeySet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ks.JWKS())
+}
+
+/*
+Keyfunc verifies tokens with the key named by the kid in their header. The token has to be signed with a method
+fitting the key, and with the alg of its JWK if that names one.
+*/
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("token has no kid")
+	}
+	ks.mu.RLock()
+	entry, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+	if !ok {
+		return nil, errUnknownKey
+	}
+	if entry.jwk.Alg != "" && entry.jwk.Alg != token.Method.Alg() {
+		return nil, fmt.Errorf("signing method %s doesn't match the alg %s of key %s", token.Method.Alg(),
+			entry.jwk.Alg, kid)
+	}
+	if err := checkMethod(token.Method, entry.pub); err != nil {
+		return nil, err
+	}
+	return entry.pub, nil
+}
+
+// Defaults used for the zero values of the fields of JWKSResolver.
+const (
+	DefaultJWKSMaxAge          = time.Hour
+	DefaultJWKSRefreshInterval = time.Minute
+	DefaultJWKSTimeout         = 10 * time.Second
+)
+
+// defaultJWKSClient fetches the keys of resolvers without an HTTPClient. Unlike http.DefaultClient it gives up.
+var defaultJWKSClient = &http.Client{Timeout: DefaultJWKSTimeout}
+
+// maxJWKSSize limits the JWKS document a JWKSResolver reads.
+const maxJWKSSize = 1 << 20
+
+/*
+JWKSResolver verifies tokens with the keys an issuer publishes at its JWKS endpoint URL. Use its Keyfunc as the
+Keyfunc of an Authenticator.
+
+The keys are fetched on first use and cached for MaxAge. A token with a kid the cache doesn't know makes the resolver
+fetch the keys again right away, which is how it picks up a key the issuer rotated to. To keep tokens with made up
+kids from hammering the issuer the keys are fetched at most once per RefreshInterval. If a fetch fails, the keys
+fetched before stay in use. Tokens arriving while the keys are fetched wait for that fetch instead of starting their
+own. Zero fields fall back to an HTTP client with a timeout of DefaultJWKSTimeout and the other Default constants.
+*/
+type JWKSResolver struct {
+	URL             string
+	HTTPClient      *http.Client
+	MaxAge          time.Duration
+	RefreshInterval time.Duration
+
+	mu        sync.Mutex
+	keys      *KeySet
+	fetched   time.Time
+	attempted time.Time
+	err       error
+	fetching  chan struct{} // closed once the fetch in flight is done, nil if there is none
+	now       func() time.Time
+}
+
+// Keyfunc returns the published key named by the kid of token, see KeySet.Keyfunc.
+func (jr *JWKSResolver) Keyfunc(token *jwt.Token) (interface{}, error) {
+	now := time.Now()
+	if jr.now != nil {
+		now = jr.now()
+	}
+	maxAge := jr.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultJWKSMaxAge
+	}
+	keys, fetched := jr.cached()
+	if keys == nil || now.Sub(fetched) >= maxAge {
+		err := jr.refresh(now)
+		keys, _ = jr.cached()
+		if keys == nil {
+			return nil, fmt.Errorf("fetching JWKS from %s: %v", jr.URL, err)
+		}
+	}
+	key, err := keys.Keyfunc(token)
+	if err == errUnknownKey && jr.refresh(now) == nil {
+		keys, _ = jr.cached()
+		key, err = keys.Keyfunc(token)
+	}
+	return key, err
+}
+
+// cached returns the keys fetched last and when they were fetched.
+func (jr *JWKSResolver) cached() (*KeySet, time.Time) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	return jr.keys, jr.fetched
+}
+
+/*
+refresh fetches the keys unless it tried less than RefreshInterval ago. jr.mu isn't held during the fetch, so a slow
+issuer only holds up the tokens which need new keys. If a fetch is in flight already, refresh waits for it and returns
+its error.
+*/
+func (jr *JWKSResolver) refresh(now time.Time) error {
+	jr.mu.Lock()
+	if done := jr.fetching; done != nil {
+		jr.mu.Unlock()
+		<-done
+		jr.mu.Lock()
+		defer jr.mu.Unlock()
+		return jr.err
+	}
+	interval := jr.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultJWKSRefreshInterval
+	}
+	if !jr.attempted.IsZero() && now.Sub(jr.attempted) < interval {
+		defer jr.mu.Unlock()
+		if jr.err == nil {
+			return fmt.Errorf("fetched %s less than %s ago", jr.URL, interval)
+		}
+		return jr.err
+	}
+	jr.attempted = now
+	done := make(chan struct{})
+	jr.fetching = done
+	jr.mu.Unlock()
+
+	keys, err := jr.fetch()
+
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	jr.fetching = nil
+	close(done)
+	jr.err = err
+	if err != nil {
+		return err
+	}
+	jr.keys = keys
+	jr.fetched = now
+	return nil
+}
+
+// fetch gets the JWKS document. Keys not meant for signatures or of unsupported types are left out.
+func (jr *JWKSResolver) fetch() (*KeySet, error) {
+	client := jr.HTTPClient
+	if client == nil {
+		client = defaultJWKSClient
+	}
+	res, err := client.Get(jr.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", jr.URL, res.Status)
+	}
+	var set JWKSet
+	err = json.NewDecoder(io.LimitReader(res.Body, maxJWKSSize)).Decode(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS from %s: %v", jr.URL, err)
+	}
+	keys := &KeySet{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys.add(jwk, pub)
+	}
+	return keys, nil
+}
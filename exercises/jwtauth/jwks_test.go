@@ -0,0 +1,172 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeySet(t *testing.T) {
+	keys := &KeySet{}
+	key1, key2 := newEd25519Key(t), newEd25519Key(t)
+	for kid, key := range map[string]crypto.Signer{"1": key1, "2": key2} {
+		if err := keys.Add(kid, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := keys.Add("", key1); err == nil {
+		t.Error("Expected an error for a key without kid")
+	}
+	keys.Remove("1")
+	if set := keys.JWKS(); len(set.Keys) != 1 || set.Keys[0].Kid != "2" {
+		t.Errorf("After removing key 1 expected only key 2 got %+v", set)
+	}
+
+	tests := []struct {
+		name  string
+		kid   string
+		key   crypto.Signer
+		valid bool
+	}{
+		{"current key", "2", key2, true},
+		{"removed key", "1", key1, false},
+		{"key of another kid", "2", key1, false},
+		{"no kid", "", key2, false},
+	}
+	for _, test := range tests {
+		token, err := Sign(jwt.MapClaims{}, test.kid, test.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = jwt.Parse(token, keys.Keyfunc)
+		if (err == nil) != test.valid {
+			t.Errorf("For %s expected valid %t got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestJWKSResolver(t *testing.T) {
+	keys := &KeySet{}
+	key1, key2 := newEd25519Key(t), newEd25519Key(t)
+	keys.Add("1", key1)
+	var fetches int32
+	down := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		keys.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	resolver := &JWKSResolver{URL: ts.URL, MaxAge: time.Hour, RefreshInterval: time.Minute}
+	resolver.now = func() time.Time {
+		return now
+	}
+	verify := func(name, kid string, key crypto.Signer, valid bool, expectedFetches int32) {
+		token, err := Sign(jwt.MapClaims{}, kid, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = jwt.Parse(token, resolver.Keyfunc)
+		if (err == nil) != valid {
+			t.Errorf("For %s expected valid %t got %v", name, valid, err)
+		}
+		if n := atomic.LoadInt32(&fetches); n != expectedFetches {
+			t.Errorf("For %s expected %d fetches got %d", name, expectedFetches, n)
+		}
+	}
+
+	verify("first token", "1", key1, true, 1)
+	verify("cached key", "1", key1, true, 1)
+	verify("unknown kid right after a fetch", "2", key2, false, 1)
+	now = now.Add(time.Minute)
+	verify("unknown kid", "2", key2, false, 2)
+	// The issuer rotates to key 2, the resolver waits RefreshInterval before it looks again.
+	keys.Add("2", key2)
+	verify("rotated key right after a fetch", "2", key2, false, 2)
+	now = now.Add(time.Minute)
+	verify("rotated key", "2", key2, true, 3)
+	verify("old key", "1", key1, true, 3)
+
+	// The keys fetched before outlive a failing issuer.
+	down = true
+	now = now.Add(time.Hour)
+	verify("cached key while the issuer is down", "2", key2, true, 4)
+	down = false
+	keys.Remove("1")
+	now = now.Add(time.Minute)
+	verify("removed key", "1", key1, false, 5)
+}
+
+func TestJWKSResolverFetchesOnce(t *testing.T) {
+	keys := &KeySet{}
+	key := newEd25519Key(t)
+	keys.Add("1", key)
+	var fetches int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		keys.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	token, err := Sign(jwt.MapClaims{}, "1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens arriving while the issuer is slow wait for the one fetch in flight.
+	resolver := &JWKSResolver{URL: ts.URL}
+	errs := make(chan error)
+	for i := 0; i < 5; i++ {
+		go func() {
+			_, err := jwt.Parse(token, resolver.Keyfunc)
+			errs <- err
+		}()
+	}
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Expected the token to be valid got %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("Expected 1 fetch for tokens arriving together got %d", n)
+	}
+
+	// A hung issuer times out instead of holding up tokens forever.
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hung.Close()
+	resolver = &JWKSResolver{URL: hung.URL, HTTPClient: &http.Client{Timeout: 50 * time.Millisecond}}
+	if _, err := jwt.Parse(token, resolver.Keyfunc); err == nil {
+		t.Error("Expected the token to be rejected while the issuer hangs")
+	}
+	if defaultJWKSClient.Timeout <= 0 {
+		t.Error("Expected the default client to time out")
+	}
+}
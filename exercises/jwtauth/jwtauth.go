@@ -0,0 +1,144 @@
+/*
+Package jwtauth authenticates HTTP requests with JSON Web Tokens. An Authenticator takes the token from where its
+Extractor looks, checks the signature with the key its Keyfunc returns and hands the claims to the next handler in the
+request context, where ClaimsFromContext finds them. Requests without a valid token are answered with 401 Unauthorized
+and a JSON body such as
+
+	{"error": "invalid_token", "message": "token is expired"}
+
+Tokens are verified with a shared HMAC secret, see HMACKey, or with public keys, so verifiers can't mint tokens: a
+single key given to PublicKey, the KeySet of the issuer or the keys the issuer publishes at its JWKS endpoint, fetched
+by a JWKSResolver. Ed25519 keys sign with the EdDSA method this package adds to jwt-go.
+
+Once a request is authenticated, Require lets it through only if its claims meet requirements such as HasRole and
+HasScope, and answers it with 403 Forbidden and the reason otherwise.
+*/
+package jwtauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Codes in the error field of the JSON error bodies.
+const (
+	CodeMissingToken   = "missing_token"
+	CodeMalformedToken = "malformed_token"
+	CodeInvalidToken   = "invalid_token"
+)
+
+// HMACKey returns a Keyfunc verifying tokens signed with HS256, HS384 or HS512 and secret.
+func HMACKey(secret []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		// Without this check a token could pick an algorithm the secret means something else to.
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return secret, nil
+	}
+}
+
+/*
+Authenticator checks the tokens of requests. Keyfunc has to be set, without one every token is rejected. Extract
+defaults to FromBearer. The claims of tokens with a valid signature are checked against Policy, the zero Policy if it
+is nil. With a Denylist tokens whose jti it holds are rejected.
+*/
+type Authenticator struct {
+	Extract  Extractor
+	Keyfunc  jwt.Keyfunc
+	Policy   *Policy
+	Denylist Denylist
+}
+
+// ErrRevokedToken is returned by Authenticate for a token on the Denylist.
+var ErrRevokedToken = errors.New("token has been revoked")
+
+/*
+Authenticate returns the claims of the token of r. The error is ErrMissingToken or ErrMalformedToken if r has no
+usable token and otherwise tells why the token is invalid.
+*/
+func (a *Authenticator) Authenticate(r *http.Request) (jwt.MapClaims, error) {
+	extract := a.Extract
+	if extract == nil {
+		extract = FromBearer()
+	}
+	tokenString, err := extract(r)
+	if err != nil {
+		return nil, err
+	}
+	// The Policy checks exp, nbf and iat, unlike jwt-go with leeway.
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	claims := jwt.MapClaims{}
+	token, err := parser.ParseWithClaims(tokenString, claims, a.Keyfunc)
+	if ve, ok := err.(*jwt.ValidationError); ok && ve.Errors&jwt.ValidationErrorMalformed != 0 {
+		return nil, ErrMalformedToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	policy := a.Policy
+	if policy == nil {
+		policy = &Policy{}
+	}
+	err = policy.Validate(claims)
+	if err != nil {
+		return nil, err
+	}
+	if jti, _ := claims["jti"].(string); jti != "" && a.Denylist != nil && a.Denylist.Revoked(jti) {
+		return nil, ErrRevokedToken
+	}
+	return claims, nil
+}
+
+// Middleware passes requests with a valid token on to next and answers all others with 401 Unauthorized.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := a.Authenticate(r)
+		if err != nil {
+			code := CodeInvalidToken
+			switch err {
+			case ErrMissingToken:
+				code = CodeMissingToken
+			case ErrMalformedToken:
+				code = CodeMalformedToken
+			}
+			WriteError(w, http.StatusUnauthorized, code, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+	})
+}
+
+// contextKey is the key of the claims in a context. Being unexported it can't collide with keys of other packages.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying claims.
+func NewContext(ctx context.Context, claims jwt.MapClaims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims the Middleware stored in ctx, if any.
+func ClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(jwt.MapClaims)
+	return claims, ok
+}
+
+type errorBody struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// WriteError answers a request with status and a JSON body holding code and message.
+func WriteError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody{Error: code, Message: message})
+}
@@ -0,0 +1,123 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var secret = []byte("secret")
+
+func inAnHour() int64 {
+	return time.Now().Add(time.Hour).Unix()
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExtractors(t *testing.T) {
+	tests := []struct {
+		name     string
+		extract  Extractor
+		setup    func(r *http.Request)
+		expected string
+		err      error
+	}{
+		{"header", FromHeader("Token"), func(r *http.Request) { r.Header.Set("Token", "abc") }, "abc", nil},
+		{"missing header", FromHeader("Token"), func(r *http.Request) {}, "", ErrMissingToken},
+		{"bearer", FromBearer(), func(r *http.Request) { r.Header.Set("Authorization", "Bearer abc") }, "abc", nil},
+		{"lower case bearer", FromBearer(), func(r *http.Request) { r.Header.Set("Authorization", "bearer abc") },
+			"abc", nil},
+		{"basic", FromBearer(), func(r *http.Request) { r.Header.Set("Authorization", "Basic abc") }, "",
+			ErrMalformedToken},
+		{"empty bearer", FromBearer(), func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") }, "",
+			ErrMalformedToken},
+		{"missing authorization", FromBearer(), func(r *http.Request) {}, "", ErrMissingToken},
+		{"cookie", FromCookie("jwt"), func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "jwt", Value: "abc"}) },
+			"abc", nil},
+		{"missing cookie", FromCookie("jwt"), func(r *http.Request) {}, "", ErrMissingToken},
+		{"query", FromQuery("token"), func(r *http.Request) { r.URL.RawQuery = "token=abc" }, "abc", nil},
+		{"missing query", FromQuery("token"), func(r *http.Request) {}, "", ErrMissingToken},
+		{"first of", FirstOf(FromBearer(), FromQuery("token")), func(r *http.Request) { r.URL.RawQuery = "token=abc" },
+			"abc", nil},
+		{"first of stops at malformed", FirstOf(FromBearer(), FromQuery("token")), func(r *http.Request) {
+			r.Header.Set("Authorization", "Basic abc")
+			r.URL.RawQuery = "token=abc"
+		}, "", ErrMalformedToken},
+		{"first of nothing", FirstOf(FromBearer(), FromQuery("token")), func(r *http.Request) {}, "", ErrMissingToken},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		test.setup(r)
+		token, err := test.extract(r)
+		if token != test.expected || err != test.err {
+			t.Errorf("For %s expected %q, %v got %q, %v", test.name, test.expected, test.err, token, err)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	auth := Authenticator{Keyfunc: HMACKey(secret)}
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			t.Error("Expected claims in the context")
+		}
+		w.Write([]byte(claims["sub"].(string)))
+	}))
+
+	valid := sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": inAnHour()})
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		code          string
+	}{
+		{"valid token", "Bearer " + valid, http.StatusOK, ""},
+		{"no token", "", http.StatusUnauthorized, CodeMissingToken},
+		{"basic auth", "Basic YWxpY2U6c2VjcmV0", http.StatusUnauthorized, CodeMalformedToken},
+		{"no jwt", "Bearer abc", http.StatusUnauthorized, CodeMalformedToken},
+		{"wrong secret", "Bearer " + sign(t, jwt.SigningMethodHS256, []byte("guess"),
+			jwt.MapClaims{"exp": inAnHour()}),
+			http.StatusUnauthorized, CodeInvalidToken},
+		{"expired", "Bearer " + sign(t, jwt.SigningMethodHS256, secret,
+			jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), http.StatusUnauthorized, CodeInvalidToken},
+		{"unsigned", "Bearer " + sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType,
+			jwt.MapClaims{"exp": inAnHour()}), http.StatusUnauthorized, CodeInvalidToken},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.authorization != "" {
+			r.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("For %s expected status %d got %d", test.name, test.status, w.Code)
+			continue
+		}
+		if test.status == http.StatusOK {
+			if w.Body.String() != "alice" {
+				t.Errorf("For %s expected the claims of the token got %q", test.name, w.Body.String())
+			}
+			continue
+		}
+		var body errorBody
+		err := json.Unmarshal(w.Body.Bytes(), &body)
+		if err != nil || body.Error != test.code || body.Message == "" {
+			t.Errorf("For %s expected error %s got %q", test.name, test.code, w.Body.String())
+		}
+		if w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("For %s expected a JSON body got %s", test.name, w.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+/*
+ParsePrivateKeyPEM parses the first PEM block of data as an RSA, ECDSA or Ed25519 private key, in PKCS #1, SEC 1 or
+PKCS #8 form as written by openssl genrsa, openssl ecparam -genkey and openssl genpkey.
+*/
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("PEM block %q holds no private key", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
+
+/*
+ParsePublicKeyPEM parses the first PEM block of data as an RSA, ECDSA or Ed25519 public key. Besides public keys in
+PKIX or PKCS #1 form it takes the key of a certificate.
+*/
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("PEM block %q holds no public key", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := SigningMethod(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LoadPrivateKey reads a private key from the PEM file path.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return key, nil
+}
+
+// LoadPublicKey reads a public key from the PEM file path.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePublicKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return key, nil
+}
+
+/*
+SigningMethod returns the signing method tokens signed with key use: RS256 for RSA keys, ES256, ES384 or ES512 for
+ECDSA keys on P-256, P-384 and P-521 and EdDSA for Ed25519 keys. key may be the private or the public key.
+*/
+func SigningMethod(key interface{}) (jwt.SigningMethod, error) {
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256, nil
+		case 384:
+			return jwt.SigningMethodES384, nil
+		case 521:
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
+
+/*
+checkMethod returns an error unless method is meant for the public key pub. Verifying only with the method the key is
+meant for keeps a token from choosing how its signature is checked, RSA keys accept RS and PS algorithms of any size.
+*/
+func checkMethod(method jwt.SigningMethod, pub crypto.PublicKey) error {
+	ok := false
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		_, rs := method.(*jwt.SigningMethodRSA)
+		_, ps := method.(*jwt.SigningMethodRSAPSS)
+		ok = rs || ps
+	case *ecdsa.PublicKey:
+		m, es := method.(*jwt.SigningMethodECDSA)
+		ok = es && m.CurveBits == k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		_, ok = method.(*SigningMethodEd25519)
+	}
+	if !ok {
+		return fmt.Errorf("signing method %s doesn't fit a %T key", method.Alg(), pub)
+	}
+	return nil
+}
+
+// PublicKey returns a Keyfunc verifying tokens with pub, signed with a method fitting it.
+func PublicKey(pub crypto.PublicKey) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if err := checkMethod(token.Method, pub); err != nil {
+			return nil, err
+		}
+		return pub, nil
+	}
+}
+
+/*
+Sign returns the token of claims signed with key and the signing method SigningMethod picks for it. A non-empty kid is
+put in the header, so verifiers know which of the published keys to check the signature with.
+*/
+func Sign(claims jwt.Claims, kid string, key crypto.Signer) (string, error) {
+	method, err := SigningMethod(key)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
+}
@@ -0,0 +1,164 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+type testKey struct {
+	name string
+	key  crypto.Signer
+	pem  []byte
+	alg  string
+}
+
+func testKeys(t *testing.T) []testKey {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []testKey{
+		{"RSA", rsaKey, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), "RS256"},
+		{"ECDSA", ecKey, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}), "ES384"},
+		{"Ed25519", edKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}), "EdDSA"},
+	}
+}
+
+func TestAsymmetricKeys(t *testing.T) {
+	keys := testKeys(t)
+	for _, k := range keys {
+		priv, err := ParsePrivateKeyPEM(k.pem)
+		if err != nil {
+			t.Fatalf("For %s key: %v", k.name, err)
+		}
+		pubDER, err := x509.MarshalPKIXPublicKey(k.key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+		if err != nil {
+			t.Fatalf("For %s public key: %v", k.name, err)
+		}
+
+		token, err := Sign(jwt.MapClaims{"sub": "alice", "exp": inAnHour()}, "", priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, _ := jwt.Parse(token, nil)
+		if parsed == nil || parsed.Method.Alg() != k.alg {
+			t.Errorf("For %s key expected a token signed with %s got %v", k.name, k.alg, parsed)
+		}
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		auth := Authenticator{Keyfunc: PublicKey(pub)}
+		claims, err := auth.Authenticate(r)
+		if err != nil || claims["sub"] != "alice" {
+			t.Errorf("For %s key expected the token to verify got %v, %v", k.name, claims, err)
+		}
+
+		// A token signed with another key type must not verify, even if its signature is valid.
+		for _, other := range keys {
+			if other.name == k.name {
+				continue
+			}
+			token, err := Sign(jwt.MapClaims{}, "", other.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Header.Set("Authorization", "Bearer "+token)
+			if _, err := auth.Authenticate(r); err == nil {
+				t.Errorf("For %s key expected a token signed with %s to be rejected", k.name, other.name)
+			}
+		}
+	}
+
+	if _, err := ParsePrivateKeyPEM([]byte("no key")); err == nil {
+		t.Error("Expected an error for data without PEM block")
+	}
+}
+
+func TestJWK(t *testing.T) {
+	for _, k := range testKeys(t) {
+		jwk, err := NewJWK("kid-"+k.name, k.key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(jwk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded JWK
+		err = json.Unmarshal(data, &decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Alg != k.alg || decoded.Kid != "kid-"+k.name || decoded.Use != "sig" {
+			t.Errorf("For %s key expected alg %s got %s", k.name, k.alg, data)
+		}
+		pub, err := decoded.PublicKey()
+		if err != nil {
+			t.Fatalf("For %s key: %v", k.name, err)
+		}
+		if !reflect.DeepEqual(pub, k.key.Public()) {
+			t.Errorf("For %s key expected the JWK to hold the public key got %v", k.name, pub)
+		}
+	}
+
+	n2048 := b64.EncodeToString(append([]byte{0x80}, make([]byte, 255)...))
+	invalid := []struct {
+		name string
+		jwk  JWK
+	}{
+		{"symmetric key", JWK{Kty: "oct", Kid: "secret"}},
+		{"point off the curve", JWK{Kty: "EC", Crv: "P-256", X: b64.EncodeToString([]byte{1}),
+			Y: b64.EncodeToString([]byte{2})}},
+		{"short Ed25519 key", JWK{Kty: "OKP", Crv: "Ed25519", X: b64.EncodeToString([]byte{1, 2, 3})}},
+		{"undecodable modulus", JWK{Kty: "RSA", N: "!!", E: "AQAB"}},
+		{"1 bit modulus", JWK{Kty: "RSA", N: b64.EncodeToString([]byte{1}), E: "AQAB"}},
+		{"2047 bit modulus", JWK{Kty: "RSA", N: b64.EncodeToString(append([]byte{0x40}, make([]byte, 255)...)),
+			E: "AQAB"}},
+		{"exponent 1", JWK{Kty: "RSA", N: n2048, E: b64.EncodeToString([]byte{1})}},
+		{"exponent 0", JWK{Kty: "RSA", N: n2048, E: b64.EncodeToString([]byte{0})}},
+		{"empty exponent", JWK{Kty: "RSA", N: n2048, E: ""}},
+		{"exponent over 32 bits", JWK{Kty: "RSA", N: n2048, E: b64.EncodeToString([]byte{1, 0, 0, 0, 1})}},
+		{"exponent overflowing int", JWK{Kty: "RSA", N: n2048,
+			E: b64.EncodeToString([]byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})}},
+	}
+	for _, test := range invalid {
+		if _, err := test.jwk.PublicKey(); err == nil {
+			t.Errorf("For %s expected an error", test.name)
+		}
+	}
+	if _, err := (JWK{Kty: "RSA", N: n2048, E: "AQAB"}).PublicKey(); err != nil {
+		t.Errorf("For 2048 bit modulus expected no error got %v", err)
+	}
+}
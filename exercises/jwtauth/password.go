@@ -0,0 +1,81 @@
+package jwtauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+passwordIterations is how many PBKDF2 rounds HashPassword uses, the OWASP recommendation for PBKDF2-HMAC-SHA256.
+Tests lower it, the hashes keep their count so they stay valid.
+*/
+var passwordIterations = 600000
+
+/*
+HashPassword returns the hash of password to keep in a UserStore, in the form
+pbkdf2-sha256$<iterations>$<salt>$<hash> with salt and hash base64 encoded.
+*/
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	return formatPasswordHash(passwordIterations, salt, pbkdf2SHA256([]byte(password), salt, passwordIterations)), nil
+}
+
+func formatPasswordHash(iterations int, salt, hash []byte) string {
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", iterations, base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash))
+}
+
+// CheckPassword reports whether password matches hash as returned by HashPassword.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(pbkdf2SHA256([]byte(password), salt, iterations), expected) == 1
+}
+
+/*
+pbkdf2SHA256 derives a key of one SHA-256 block from password and salt as in RFC 8018. One block is all a password
+hash needs, so the block counter is always 1.
+*/
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	var counter [4]byte
+	binary.BigEndian.PutUint32(counter[:], 1)
+	mac.Write(counter[:])
+	u := mac.Sum(nil)
+	key := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
@@ -0,0 +1,147 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// ClaimError is returned by Policy.Validate for a claim the policy rejects.
+type ClaimError struct {
+	Claim   string
+	Message string
+}
+
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("claim %s: %s", e.Claim, e.Message)
+}
+
+/*
+Policy decides which claims a token with a valid signature needs to be accepted. Every token needs exp, and those in
+RequiredClaims. If Issuers is set iss has to be one of them, if Audiences is set aud has to name one of them. exp, nbf
+and iat are checked with Leeway to spare for clocks of issuer and verifier which are apart, and with a MaxAge tokens
+issued longer ago are rejected even before they expire, which needs iat. The zero value only requires exp.
+*/
+type Policy struct {
+	RequiredClaims []string
+	Issuers        []string
+	Audiences      []string
+	Leeway         time.Duration
+	MaxAge         time.Duration
+
+	now func() time.Time
+}
+
+// Validate returns a *ClaimError if claims break the policy.
+func (p *Policy) Validate(claims jwt.MapClaims) error {
+	now := time.Now()
+	if p.now != nil {
+		now = p.now()
+	}
+	required := append([]string{"exp"}, p.RequiredClaims...)
+	if len(p.Issuers) > 0 {
+		required = append(required, "iss")
+	}
+	if len(p.Audiences) > 0 {
+		required = append(required, "aud")
+	}
+	if p.MaxAge > 0 {
+		required = append(required, "iat")
+	}
+	for _, claim := range required {
+		if _, ok := claims[claim]; !ok {
+			return &ClaimError{Claim: claim, Message: "missing"}
+		}
+	}
+
+	exp, err := timeClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !now.Before(exp.Add(p.Leeway)) {
+		return &ClaimError{Claim: "exp", Message: "token is expired"}
+	}
+	if _, ok := claims["nbf"]; ok {
+		nbf, err := timeClaim(claims, "nbf")
+		if err != nil {
+			return err
+		}
+		if now.Add(p.Leeway).Before(nbf) {
+			return &ClaimError{Claim: "nbf", Message: "token is not valid yet"}
+		}
+	}
+	if _, ok := claims["iat"]; ok {
+		iat, err := timeClaim(claims, "iat")
+		if err != nil {
+			return err
+		}
+		if now.Add(p.Leeway).Before(iat) {
+			return &ClaimError{Claim: "iat", Message: "token is issued in the future"}
+		}
+		if p.MaxAge > 0 && now.Sub(iat) > p.MaxAge+p.Leeway {
+			return &ClaimError{Claim: "iat", Message: fmt.Sprintf("token is older than %s", p.MaxAge)}
+		}
+	}
+
+	if len(p.Issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !contains(p.Issuers, iss) {
+			return &ClaimError{Claim: "iss", Message: fmt.Sprintf("issuer %q is not accepted", iss)}
+		}
+	}
+	if len(p.Audiences) > 0 {
+		// aud is a string or an array of strings, see RFC 7519 section 4.1.3.
+		var auds []string
+		switch aud := claims["aud"].(type) {
+		case string:
+			auds = []string{aud}
+		case []interface{}:
+			for _, a := range aud {
+				if s, ok := a.(string); ok {
+					auds = append(auds, s)
+				}
+			}
+		}
+		accepted := false
+		for _, aud := range auds {
+			accepted = accepted || contains(p.Audiences, aud)
+		}
+		if !accepted {
+			return &ClaimError{Claim: "aud", Message: "token is not meant for this service"}
+		}
+	}
+	return nil
+}
+
+// timeClaim returns the NumericDate claim, seconds since the epoch.
+func timeClaim(claims jwt.MapClaims, claim string) (time.Time, error) {
+	var seconds float64
+	switch v := claims[claim].(type) {
+	case float64:
+		seconds = v
+	case int64:
+		seconds = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, &ClaimError{Claim: claim, Message: "not a number"}
+		}
+		seconds = f
+	default:
+		return time.Time{}, &ClaimError{Claim: claim, Message: "not a number"}
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestPolicy(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	at := func(d time.Duration) float64 {
+		return float64(now.Add(d).Unix())
+	}
+	strict := &Policy{
+		RequiredClaims: []string{"sub"},
+		Issuers:        []string{"https://auth.example.com", "https://sso.example.com"},
+		Audiences:      []string{"https://api.example.com"},
+		Leeway:         time.Minute,
+		MaxAge:         time.Hour,
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"sub": "alice", "iss": "https://auth.example.com", "aud": "https://api.example.com",
+			"exp": at(time.Hour), "iat": at(-time.Minute), "nbf": at(-time.Minute)}
+	}
+	with := func(claim string, v interface{}) jwt.MapClaims {
+		claims := valid()
+		if v == nil {
+			delete(claims, claim)
+		} else {
+			claims[claim] = v
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name   string
+		policy *Policy
+		claims jwt.MapClaims
+		claim  string // of the expected ClaimError, empty if the claims are valid
+	}{
+		{"valid claims", strict, valid(), ""},
+		{"zero policy", &Policy{}, jwt.MapClaims{"exp": at(time.Second)}, ""},
+		{"zero policy without exp", &Policy{}, jwt.MapClaims{"sub": "alice"}, "exp"},
+		{"missing exp", strict, with("exp", nil), "exp"},
+		{"missing required claim", strict, with("sub", nil), "sub"},
+		{"missing iss", strict, with("iss", nil), "iss"},
+		{"missing aud", strict, with("aud", nil), "aud"},
+		{"missing iat with max age", strict, with("iat", nil), "iat"},
+		{"exp no number", strict, with("exp", "tomorrow"), "exp"},
+		{"exp as json.Number", strict, with("exp", json.Number("1600003600")), ""},
+		{"expired", strict, with("exp", at(-2*time.Minute)), "exp"},
+		{"expired within leeway", strict, with("exp", at(-30*time.Second)), ""},
+		{"expired without leeway", &Policy{}, jwt.MapClaims{"exp": at(-30 * time.Second)}, "exp"},
+		{"expiring now", &Policy{}, jwt.MapClaims{"exp": at(0)}, "exp"},
+		{"not valid yet", strict, with("nbf", at(2*time.Minute)), "nbf"},
+		{"not valid yet within leeway", strict, with("nbf", at(30*time.Second)), ""},
+		{"nbf no number", strict, with("nbf", true), "nbf"},
+		{"issued in the future", strict, with("iat", at(2*time.Minute)), "iat"},
+		{"issued in the future within leeway", strict, with("iat", at(30*time.Second)), ""},
+		{"too old", strict, with("iat", at(-2*time.Hour)), "iat"},
+		{"old within leeway", strict, with("iat", at(-time.Hour-30*time.Second)), ""},
+		{"old without max age", &Policy{}, jwt.MapClaims{"exp": at(time.Hour), "iat": at(-48 * time.Hour)}, ""},
+		{"second issuer", strict, with("iss", "https://sso.example.com"), ""},
+		{"unknown issuer", strict, with("iss", "https://evil.example.com"), "iss"},
+		{"iss no string", strict, with("iss", 42), "iss"},
+		{"audience in array", strict, with("aud", []interface{}{"https://other.example.com",
+			"https://api.example.com"}), ""},
+		{"other audience", strict, with("aud", "https://other.example.com"), "aud"},
+		{"other audiences in array", strict, with("aud", []interface{}{"https://other.example.com", 42}), "aud"},
+		{"empty audience array", strict, with("aud", []interface{}{}), "aud"},
+	}
+	for _, test := range tests {
+		test.policy.now = func() time.Time {
+			return now
+		}
+		err := test.policy.Validate(test.claims)
+		if test.claim == "" {
+			if err != nil {
+				t.Errorf("For %s expected no error got %v", test.name, err)
+			}
+			continue
+		}
+		ce, ok := err.(*ClaimError)
+		if !ok || ce.Claim != test.claim {
+			t.Errorf("For %s expected an error about %s got %v", test.name, test.claim, err)
+		}
+	}
+}
+
+func TestAuthenticatorPolicy(t *testing.T) {
+	auth := Authenticator{
+		Extract: FromHeader("Token"),
+		Keyfunc: HMACKey(secret),
+		Policy:  &Policy{Issuers: []string{"https://auth.example.com"}, Leeway: time.Minute},
+	}
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		valid  bool
+	}{
+		{"valid token", jwt.MapClaims{"iss": "https://auth.example.com", "exp": inAnHour()}, true},
+		{"expired within leeway", jwt.MapClaims{"iss": "https://auth.example.com",
+			"exp": time.Now().Add(-30 * time.Second).Unix()}, true},
+		{"unknown issuer", jwt.MapClaims{"iss": "https://evil.example.com", "exp": inAnHour()}, false},
+		{"no exp", jwt.MapClaims{"iss": "https://auth.example.com"}, false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Token", sign(t, jwt.SigningMethodHS256, secret, test.claims))
+		_, err := auth.Authenticate(r)
+		if (err == nil) != test.valid {
+			t.Errorf("For %s expected valid %t got %v", test.name, test.valid, err)
+		}
+	}
+}
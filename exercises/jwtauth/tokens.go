@@ -0,0 +1,423 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Defaults used for the zero values of the fields of TokenService.
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 7 * 24 * time.Hour
+)
+
+// pruneInterval is how often logins and refreshes look for expired refresh tokens to forget.
+const pruneInterval = time.Minute
+
+// Errors of a TokenService.
+var (
+	ErrInvalidCredentials  = errors.New("invalid username or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was used before, the login is revoked")
+)
+
+// Codes in the error field of the JSON error bodies of a TokenService.
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodeInvalidRefreshToken = "invalid_refresh_token"
+	CodeServerError         = "server_error"
+)
+
+// TokenPair is what a login or refresh returns, in the form of an OAuth 2.0 token response.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // seconds the access token is valid
+	RefreshToken string `json:"refresh_token"`
+}
+
+/*
+TokenService logs users in and issues them short lived access tokens, JWTs with the claims sub, iat, exp, jti and, if
+set, iss, aud, roles and scope, together with a refresh token to get new ones. Issuer and Audience are the iss and aud a
+Policy of the services accepting the tokens checks.
+
+Refresh tokens are random strings only the service can look up. They rotate: every refresh returns a new refresh token
+and the old one is used up. The refresh tokens of a login form a family, and a used up token coming back means it was
+stolen, either the thief or the user already used it. The service then revokes the whole family, so the thief is
+locked out as soon as the user refreshes and the other way round. Logout revokes the family too.
+
+Access tokens of a revoked family go on Denylist by their jti until they expire. Authenticators with the same
+Denylist reject them, all others accept them until exp, which is why AccessTTL is kept short.
+
+Users has to be set, as well as Key or Secret: access tokens are signed with Key, named Kid in their header, or with
+Secret and HS256 if Key is nil. Refresh tokens are kept in memory, so they don't survive a restart.
+*/
+type TokenService struct {
+	Users      UserStore
+	Key        crypto.Signer
+	Kid        string
+	Secret     []byte
+	Issuer     string
+	Audience   string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	Denylist   Denylist
+
+	mu            sync.Mutex
+	refreshTokens map[string]*refreshToken
+	families      map[string]*tokenFamily
+	pruned        time.Time
+	now           func() time.Time
+}
+
+type refreshToken struct {
+	family  string
+	expires time.Time
+	used    bool
+}
+
+// tokenFamily is a login and the tokens issued to it.
+type tokenFamily struct {
+	username      string
+	revoked       bool
+	accessJTI     string // of the access token issued last
+	accessExpires time.Time
+	expires       time.Time // of the refresh token issued last
+}
+
+func (ts *TokenService) clock() time.Time {
+	if ts.now != nil {
+		return ts.now()
+	}
+	return time.Now()
+}
+
+// randomToken returns 256 random bits, base64url encoded.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(b), nil
+}
+
+// Login checks the password of username and starts a new login.
+func (ts *TokenService) Login(username, password string) (TokenPair, error) {
+	u, err := ts.Users.User(username)
+	if err == ErrUnknownUser {
+		// Hashing anyway keeps the response time from telling which usernames exist.
+		CheckPassword(formatPasswordHash(passwordIterations, make([]byte, 16), make([]byte, 32)), password)
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !CheckPassword(u.PasswordHash, password) {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	family, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	now := ts.clock()
+	ts.prune(now)
+	if ts.families == nil {
+		ts.families = make(map[string]*tokenFamily)
+		ts.refreshTokens = make(map[string]*refreshToken)
+	}
+	ts.families[family] = &tokenFamily{username: u.Username}
+	return ts.issue(u, family, now)
+}
+
+/*
+Refresh uses up refreshToken and returns a new access and refresh token of the same login. It returns
+ErrRefreshTokenReused if refreshToken was used up before, the login is then revoked. The user is looked up without
+holding mu and refreshToken is used up only once that succeeded, so a failing user store neither holds up other
+refreshes nor burns the token of a client which retries.
+*/
+func (ts *TokenService) Refresh(refreshToken string) (TokenPair, error) {
+	ts.mu.Lock()
+	now := ts.clock()
+	ts.prune(now)
+	_, f, err := ts.unusedRefreshToken(refreshToken, now)
+	ts.mu.Unlock()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	// The user may have been removed since the login.
+	u, err := ts.Users.User(f.username)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if err == ErrUnknownUser {
+		ts.revoke(f, ts.clock())
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	// Another refresh with the same token may have got through in the meantime.
+	now = ts.clock()
+	rt, f, err := ts.unusedRefreshToken(refreshToken, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	rt.used = true
+	return ts.issue(u, rt.family, now)
+}
+
+/*
+unusedRefreshToken returns the refresh token token and its login if it may be used at now. Using a refresh token
+which was used up before revokes its login. The caller has to hold mu.
+*/
+func (ts *TokenService) unusedRefreshToken(token string, now time.Time) (*refreshToken, *tokenFamily, error) {
+	rt, ok := ts.refreshTokens[token]
+	if !ok || !now.Before(rt.expires) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	f := ts.families[rt.family]
+	if f.revoked {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if rt.used {
+		ts.revoke(f, now)
+		return nil, nil, ErrRefreshTokenReused
+	}
+	return rt, f, nil
+}
+
+func (ts *TokenService) Logout(refreshToken string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	rt, ok := ts.refreshTokens[refreshToken]
+	if !ok {
+		return ErrInvalidRefreshToken
+	}
+	ts.revoke(ts.families[rt.family], ts.clock())
+	return nil
+}
+
+// issue returns new tokens of the login family of u. ts.mu has to be held.
+func (ts *TokenService) issue(u User, family string, now time.Time) (TokenPair, error) {
+	accessTTL := ts.AccessTTL
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTTL
+	}
+	refreshTTL := ts.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTTL
+	}
+	jti, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	accessExpires := now.Add(accessTTL)
+	claims := jwt.MapClaims{"sub": u.Username, "iat": now.Unix(), "exp": accessExpires.Unix(), "jti": jti}
+	if ts.Issuer != "" {
+		claims["iss"] = ts.Issuer
+	}
+	if ts.Audience != "" {
+		claims["aud"] = ts.Audience
+	}
+	if len(u.Roles) > 0 {
+		claims["roles"] = u.Roles
+	}
+	if len(u.Scopes) > 0 {
+		claims["scope"] = strings.Join(u.Scopes, " ")
+	}
+	var access string
+	if ts.Key != nil {
+		access, err = Sign(claims, ts.Kid, ts.Key)
+	} else {
+		access, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ts.Secret)
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	f := ts.families[family]
+	f.accessJTI = jti
+	f.accessExpires = accessExpires
+	f.expires = now.Add(refreshTTL)
+	ts.refreshTokens[refresh] = &refreshToken{family: family, expires: f.expires}
+	return TokenPair{AccessToken: access, TokenType: "Bearer", ExpiresIn: int64(accessTTL / time.Second),
+		RefreshToken: refresh}, nil
+}
+
+// revoke revokes the login f and puts its current access token on the denylist. ts.mu has to be held.
+func (ts *TokenService) revoke(f *tokenFamily, now time.Time) {
+	f.revoked = true
+	if ts.Denylist != nil && now.Before(f.accessExpires) {
+		ts.Denylist.Revoke(f.accessJTI, f.accessExpires)
+	}
+}
+
+/*
+prune forgets the refresh tokens which expired and the logins whose last refresh token expired. Used up tokens are
+kept until then, so their reuse is noticed. Login and Refresh both prune, so a service which mostly refreshes doesn't
+pile up used up tokens, but at most once per pruneInterval. ts.mu has to be held.
+*/
+func (ts *TokenService) prune(now time.Time) {
+	if now.Sub(ts.pruned) < pruneInterval {
+		return
+	}
+	ts.pruned = now
+	for token, rt := range ts.refreshTokens {
+		if !now.Before(rt.expires) {
+			delete(ts.refreshTokens, token)
+		}
+	}
+	for id, f := range ts.families {
+		if !now.Before(f.expires) {
+			delete(ts.families, id)
+		}
+	}
+}
+
+/*
+LoginHandler logs in with a JSON body {"username": ..., "password": ...} and answers with the TokenPair. Mount it
+together with RefreshHandler and LogoutHandler, for example at /login, /refresh and /logout.
+*/
+func (ts *TokenService) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if !decodeTokenRequest(w, r, &req) {
+		return
+	}
+	tokens, err := ts.Login(req.Username, req.Password)
+	if err == ErrInvalidCredentials {
+		WriteError(w, http.StatusUnauthorized, CodeInvalidCredentials, err.Error())
+		return
+	}
+	writeTokens(w, tokens, err)
+}
+
+// RefreshHandler refreshes with a JSON body {"refresh_token": ...} and answers with the new TokenPair.
+func (ts *TokenService) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if !decodeTokenRequest(w, r, &req) {
+		return
+	}
+	tokens, err := ts.Refresh(req.RefreshToken)
+	if err == ErrInvalidRefreshToken || err == ErrRefreshTokenReused {
+		if err == ErrRefreshTokenReused {
+			log.Println("Refresh token reused, login revoked")
+		}
+		WriteError(w, http.StatusUnauthorized, CodeInvalidRefreshToken, err.Error())
+		return
+	}
+	writeTokens(w, tokens, err)
+}
+
+// LogoutHandler revokes the login of the JSON body {"refresh_token": ...} and answers with 204 No Content.
+func (ts *TokenService) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if !decodeTokenRequest(w, r, &req) {
+		return
+	}
+	err := ts.Logout(req.RefreshToken)
+	if err != nil {
+		WriteError(w, http.StatusUnauthorized, CodeInvalidRefreshToken, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeTokenRequest decodes the JSON body of a POST to the token service into v or answers the request itself.
+func decodeTokenRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		WriteError(w, http.StatusMethodNotAllowed, CodeInvalidRequest, "only POST is allowed")
+		return false
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(v)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeTokens(w http.ResponseWriter, tokens TokenPair, err error) {
+	if err != nil {
+		log.Println("Error while issuing tokens", err)
+		WriteError(w, http.StatusInternalServerError, CodeServerError, "tokens could not be issued")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// Tokens must not end up in caches, see RFC 6749 section 5.1.
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+/*
+Denylist holds the jti of revoked tokens until the tokens expire. Set the same Denylist on the TokenService issuing
+the tokens and the Authenticators checking them.
+*/
+type Denylist interface {
+	Revoke(jti string, until time.Time)
+	Revoked(jti string) bool
+}
+
+/*
+MemoryDenylist is a Denylist in memory, for Authenticators in the process of the TokenService. The zero value is ready
+to use.
+*/
+type MemoryDenylist struct {
+	mu   sync.Mutex
+	jtis map[string]time.Time
+	now  func() time.Time
+}
+
+func (md *MemoryDenylist) clock() time.Time {
+	if md.now != nil {
+		return md.now()
+	}
+	return time.Now()
+}
+
+func (md *MemoryDenylist) Revoke(jti string, until time.Time) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+	now := md.clock()
+	for j, u := range md.jtis {
+		if !now.Before(u) {
+			delete(md.jtis, j)
+		}
+	}
+	if md.jtis == nil {
+		md.jtis = make(map[string]time.Time)
+	}
+	md.jtis[jti] = until
+}
+
+func (md *MemoryDenylist) Revoked(jti string) bool {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+	until, ok := md.jtis[jti]
+	return ok && md.clock().Before(until)
+}
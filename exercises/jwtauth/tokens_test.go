@@ -0,0 +1,307 @@
+package jwtauth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPassword(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vector of RFC 7914, section 11.
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1)
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"
+	if hex.EncodeToString(key) != expected {
+		t.Errorf("Expected PBKDF2 key %s got %x", expected, key)
+	}
+
+	passwordIterations = 1000
+	hash, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == other {
+		t.Error("Expected hashes of the same password to differ by their salt")
+	}
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		expected bool
+	}{
+		{"right password", hash, "hunter2", true},
+		{"wrong password", hash, "hunter3", false},
+		{"empty password", hash, "", false},
+		{"unknown scheme", "bcrypt$10$abc$def", "hunter2", false},
+		{"malformed hash", "pbkdf2-sha256$x$abc$def", "hunter2", false},
+		{"no hash", "", "", false},
+	}
+	for _, test := range tests {
+		if CheckPassword(test.hash, test.password) != test.expected {
+			t.Errorf("For %s expected %t", test.name, test.expected)
+		}
+	}
+}
+
+func newTestTokenService(t *testing.T) (*TokenService, *Authenticator, *time.Time) {
+	passwordIterations = 1000
+	hash, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	clock := func() time.Time {
+		return now
+	}
+	denylist := &MemoryDenylist{now: clock}
+	ts := &TokenService{
+		Users: UserMap{"alice": {Username: "alice", PasswordHash: hash, Roles: []string{"admin"},
+			Scopes: []string{"uploads:read", "uploads:write"}}},
+		Secret:    secret,
+		Issuer:    "https://auth.example.com",
+		AccessTTL: 5 * time.Minute,
+		Denylist:  denylist,
+		now:       clock,
+	}
+	return ts, &Authenticator{Keyfunc: HMACKey(secret), Denylist: denylist}, &now
+}
+
+// checkAccess returns the error of authenticating with the access token of tokens.
+func checkAccess(auth *Authenticator, tokens TokenPair) error {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	_, err := auth.Authenticate(r)
+	return err
+}
+
+func TestTokenServiceLogin(t *testing.T) {
+	ts, auth, _ := newTestTokenService(t)
+
+	for _, credentials := range [][2]string{{"alice", "hunter3"}, {"bob", "hunter2"}, {"", ""}} {
+		if _, err := ts.Login(credentials[0], credentials[1]); err != ErrInvalidCredentials {
+			t.Errorf("For login of %q with %q expected %v got %v", credentials[0], credentials[1],
+				ErrInvalidCredentials, err)
+		}
+	}
+	tokens, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn != 300 || tokens.RefreshToken == "" {
+		t.Errorf("Unexpected tokens %+v", tokens)
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	claims, err := auth.Authenticate(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "alice" || claims["iss"] != "https://auth.example.com" || claims["jti"] == "" ||
+		!reflect.DeepEqual(claims["roles"], []interface{}{"admin"}) ||
+		claims["scope"] != "uploads:read uploads:write" {
+		t.Errorf("Unexpected claims %v", claims)
+	}
+}
+
+func TestTokenServiceRefresh(t *testing.T) {
+	ts, auth, now := newTestTokenService(t)
+	first, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ts.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("Expected refresh to rotate both tokens")
+	}
+	if err := checkAccess(auth, second); err != nil {
+		t.Errorf("Expected the refreshed access token to be valid got %v", err)
+	}
+
+	// Someone replays the first refresh token: the login is revoked for everybody.
+	if _, err := ts.Refresh(first.RefreshToken); err != ErrRefreshTokenReused {
+		t.Errorf("For reused refresh token expected %v got %v", ErrRefreshTokenReused, err)
+	}
+	if _, err := ts.Refresh(second.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("For refresh token of a revoked login expected %v got %v", ErrInvalidRefreshToken, err)
+	}
+	if err := checkAccess(auth, second); err != ErrRevokedToken {
+		t.Errorf("For access token of a revoked login expected %v got %v", ErrRevokedToken, err)
+	}
+
+	// Other logins are left alone, but their refresh tokens expire.
+	other, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkAccess(auth, other); err != nil {
+		t.Errorf("Expected the access token of another login to be valid got %v", err)
+	}
+	*now = now.Add(DefaultRefreshTTL)
+	if _, err := ts.Refresh(other.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("For expired refresh token expected %v got %v", ErrInvalidRefreshToken, err)
+	}
+	if _, err := ts.Refresh("made up"); err != ErrInvalidRefreshToken {
+		t.Errorf("For unknown refresh token expected %v got %v", ErrInvalidRefreshToken, err)
+	}
+}
+
+// flakyUsers is a UserStore whose first lookups fail with err.
+type flakyUsers struct {
+	UserStore
+	failures int
+	err      error
+}
+
+func (fu *flakyUsers) User(username string) (User, error) {
+	if fu.failures > 0 {
+		fu.failures--
+		return User{}, fu.err
+	}
+	return fu.UserStore.User(username)
+}
+
+func TestTokenServiceRefreshRetry(t *testing.T) {
+	ts, auth, _ := newTestTokenService(t)
+	tokens, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unavailable := errors.New("user store unavailable")
+	ts.Users = &flakyUsers{UserStore: ts.Users, failures: 1, err: unavailable}
+
+	// The failed refresh doesn't use up the refresh token, so retrying it isn't taken for reuse.
+	if _, err := ts.Refresh(tokens.RefreshToken); err != unavailable {
+		t.Errorf("For refresh with failing user store expected %v got %v", unavailable, err)
+	}
+	refreshed, err := ts.Refresh(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("For retried refresh expected no error got %v", err)
+	}
+	if err := checkAccess(auth, refreshed); err != nil {
+		t.Errorf("Expected the access token of the retried refresh to be valid got %v", err)
+	}
+}
+
+func TestTokenServicePrune(t *testing.T) {
+	ts, _, now := newTestTokenService(t)
+	start := *now
+	idle, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A service which only refreshes forgets the expired tokens all the same.
+	*now = start.Add(DefaultRefreshTTL / 2)
+	tokens, err = ts.Refresh(tokens.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*now = start.Add(DefaultRefreshTTL + pruneInterval)
+	tokens, err = ts.Refresh(tokens.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ts.refreshTokens[idle.RefreshToken]; ok || len(ts.refreshTokens) != 2 || len(ts.families) != 1 {
+		t.Errorf("Expected the used up and the current refresh token of one login to be left got %d tokens of %d logins",
+			len(ts.refreshTokens), len(ts.families))
+	}
+}
+
+func TestTokenServiceLogout(t *testing.T) {
+	ts, auth, _ := newTestTokenService(t)
+	tokens, err := ts.Login("alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ts.Logout(tokens.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkAccess(auth, tokens); err != ErrRevokedToken {
+		t.Errorf("For access token after logout expected %v got %v", ErrRevokedToken, err)
+	}
+	if _, err := ts.Refresh(tokens.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("For refresh token after logout expected %v got %v", ErrInvalidRefreshToken, err)
+	}
+	if err := ts.Logout("made up"); err != ErrInvalidRefreshToken {
+		t.Errorf("For logout with unknown refresh token expected %v got %v", ErrInvalidRefreshToken, err)
+	}
+}
+
+func TestTokenServiceHandlers(t *testing.T) {
+	ts, _, _ := newTestTokenService(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", ts.LoginHandler)
+	mux.HandleFunc("/refresh", ts.RefreshHandler)
+	mux.HandleFunc("/logout", ts.LogoutHandler)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	w := post("/login", `{"username": "alice", "password": "hunter2"}`)
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("For login expected status %d got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	var tokens TokenPair
+	err := json.Unmarshal(w.Body.Bytes(), &tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"wrong password", "/login", `{"username": "alice", "password": "hunter3"}`, http.StatusUnauthorized,
+			CodeInvalidCredentials},
+		{"no JSON", "/login", `username=alice`, http.StatusBadRequest, CodeInvalidRequest},
+		{"refresh", "/refresh", `{"refresh_token": "` + tokens.RefreshToken + `"}`, http.StatusOK, ""},
+		{"reused refresh token", "/refresh", `{"refresh_token": "` + tokens.RefreshToken + `"}`,
+			http.StatusUnauthorized, CodeInvalidRefreshToken},
+		{"logout", "/logout", `{"refresh_token": "` + tokens.RefreshToken + `"}`, http.StatusNoContent, ""},
+		{"logout with unknown token", "/logout", `{"refresh_token": "x"}`, http.StatusUnauthorized,
+			CodeInvalidRefreshToken},
+	}
+	for _, test := range tests {
+		w := post(test.path, test.body)
+		if w.Code != test.status {
+			t.Errorf("For %s expected status %d got %d: %s", test.name, test.status, w.Code, w.Body)
+			continue
+		}
+		if test.code == "" {
+			continue
+		}
+		var body errorBody
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body.Error != test.code {
+			t.Errorf("For %s expected error %s got %s", test.name, test.code, w.Body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("For GET expected status %d got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package jwtauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// ErrUnknownUser is returned by a UserStore for a username it doesn't know.
+var ErrUnknownUser = errors.New("unknown user")
+
+/*
+User is an account tokens are issued to. PasswordHash is the result of HashPassword. Roles and Scopes end up in the
+tokens, for HasRole and HasScope.
+*/
+type User struct {
+	Username     string   `json:"username"`
+	PasswordHash string   `json:"password_hash"`
+	Roles        []string `json:"roles,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// UserStore finds the users a TokenService logs in.
+type UserStore interface {
+	User(username string) (User, error)
+}
+
+// UserMap is a UserStore keeping the users in memory, by username.
+type UserMap map[string]User
+
+func (um UserMap) User(username string) (User, error) {
+	u, ok := um[username]
+	if !ok {
+		return User{}, ErrUnknownUser
+	}
+	return u, nil
+}
+
+/*
+LoadUsers reads a UserMap from the JSON file path, which lists the users as in
+
+	{"users": [{"username": "elliot", "password_hash": "pbkdf2-sha256$...", "roles": ["reader"]}]}
+*/
+func LoadUsers(path string) (UserMap, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Users []User `json:"users"`
+	}
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid users file %s: %v", path, err)
+	}
+	users := make(UserMap, len(file.Users))
+	for _, u := range file.Users {
+		if u.Username == "" {
+			return nil, fmt.Errorf("user without username in %s", path)
+		}
+		users[u.Username] = u
+	}
+	return users, nil
+}
@@ -1,20 +1,52 @@
 package main
 
 import (
+	"crypto"
+	"flag"
 	"fmt"
-	jwt "github.com/dgrijalva/jwt-go"
+	"golang-tutorial/exercises/jwtauth"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"time"
 )
 
 var mySigningKey = []byte("captainjacksparrowsayshi")
 
+var (
+	keyFile      = flag.String("key", "", "PEM file with an RSA, ECDSA or Ed25519 private key to sign with instead of the shared secret")
+	keyID        = flag.String("kid", "1", "kid of the -key, published at /.well-known/jwks.json")
+	usersFile    = flag.String("users", "users.json", "JSON file with the users who may log in")
+	hashPassword = flag.String("hash-password", "", "print the hash of a password for the users file and exit")
+)
+
+// privateKey is the key loaded from -key, nil if tokens are signed with mySigningKey.
+var privateKey crypto.Signer
+
+// The iss and aud of the tokens, which the server checks.
+const (
+	issuer   = "http://localhost:9001"
+	audience = "http://localhost:9000"
+)
+
+// tokens issues the tokens, set up in main.
+var tokens *jwtauth.TokenService
+
+/*
+homePage logs in with the basic auth credentials of the request, e.g. curl -u elliot:captainjacksparrow, and calls the
+server with the access token.
+*/
 func homePage(w http.ResponseWriter, r *http.Request) {
-	validToken, err := GenerateJWT()
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="jwt client"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	validToken, err := GenerateJWT(username, password)
 	if err != nil {
-		fmt.Println("Failed to generate token")
+		fmt.Println("Failed to generate token:", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
 	}
 
 	client := &http.Client{}
@@ -32,31 +64,56 @@ func homePage(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, string(body))
 }
 
-func GenerateJWT() (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	claims := token.Claims.(jwt.MapClaims)
-
-	claims["authorized"] = true
-	claims["client"] = "Elliot Forbes"
-	claims["exp"] = time.Now().Add(time.Minute * 30).Unix()
-
-	tokenString, err := token.SignedString(mySigningKey)
-
+// GenerateJWT logs username in and returns a short lived access token for the server.
+func GenerateJWT(username, password string) (string, error) {
+	pair, err := tokens.Login(username, password)
 	if err != nil {
-		fmt.Errorf("Something Went Wrong: %s", err.Error())
 		return "", err
 	}
-
-	return tokenString, nil
+	return pair.AccessToken, nil
 }
 
 func handleRequests() {
 	http.HandleFunc("/", homePage)
+	// The token service for other clients, they log in, refresh and log out with JSON.
+	http.HandleFunc("/login", tokens.LoginHandler)
+	http.HandleFunc("/refresh", tokens.RefreshHandler)
+	http.HandleFunc("/logout", tokens.LogoutHandler)
+	if privateKey != nil {
+		// Servers verify the tokens with the public key published here, see the -jwks-url of the server.
+		keys := &jwtauth.KeySet{}
+		err := keys.Add(*keyID, privateKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		http.Handle("/.well-known/jwks.json", keys)
+	}
 
 	log.Fatal(http.ListenAndServe(":9001", nil))
 }
 
 func main() {
+	flag.Parse()
+	if *hashPassword != "" {
+		hash, err := jwtauth.HashPassword(*hashPassword)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(hash)
+		return
+	}
+	if *keyFile != "" {
+		key, err := jwtauth.LoadPrivateKey(*keyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		privateKey = key
+	}
+	users, err := jwtauth.LoadUsers(*usersFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokens = &jwtauth.TokenService{Users: users, Key: privateKey, Kid: *keyID, Secret: mySigningKey, Issuer: issuer,
+		Audience: audience}
 	handleRequests()
 }
\ No newline at end of file
@@ -1,48 +1,67 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"github.com/dgrijalva/jwt-go"
+	"golang-tutorial/exercises/jwtauth"
 	"log"
 	"net/http"
+	"time"
 )
 
 var mySigningKey = []byte("captainjacksparrowsayshi")
 
+// policy accepts the tokens the client issues for this server, given the clocks of both are no more than 30s apart.
+var policy = &jwtauth.Policy{
+	RequiredClaims: []string{"sub"},
+	Issuers:        []string{"http://localhost:9001"},
+	Audiences:      []string{"http://localhost:9000"},
+	Leeway:         30 * time.Second,
+}
+
+var jwksURL = flag.String("jwks-url", "", "JWKS endpoint of the issuer, e.g. http://localhost:9001/.well-known/jwks.json, the shared secret is used if empty")
+
 func homePage(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Hello World")
 	fmt.Println("Endpoint Hit: homePage")
 
 }
 
-func isAuthorized(endpoint func(http.ResponseWriter, *http.Request)) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header["Token"] != nil {
-			token, err := jwt.Parse(r.Header["Token"][0], func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("There was an error")
-				}
-				return mySigningKey, nil
-			})
-
-			if err != nil {
-				fmt.Fprintf(w, err.Error())
-			}
-
-			if token.Valid {
-				endpoint(w, r)
-			}
-		} else {
-			fmt.Fprintf(w, "Not Authorized")
-		}
-	})
+/*
+newAuthenticator returns the Authenticator of the tokens in the Token header. The token is either signed with
+mySigningKey or, given a jwksURL, with one of the keys the issuer publishes there. Then the server can't mint tokens
+itself. All routes share the one Authenticator, so the keys are fetched and cached once for the whole server.
+*/
+func newAuthenticator(jwksURL string) *jwtauth.Authenticator {
+	auth := &jwtauth.Authenticator{Extract: jwtauth.FromHeader("Token"), Keyfunc: jwtauth.HMACKey(mySigningKey),
+		Policy: policy}
+	if jwksURL != "" {
+		resolver := &jwtauth.JWKSResolver{URL: jwksURL}
+		auth.Keyfunc = resolver.Keyfunc
+	}
+	return auth
+}
+
+// isAuthorized lets only requests with a token auth accepts through to endpoint.
+func isAuthorized(auth *jwtauth.Authenticator, endpoint func(http.ResponseWriter, *http.Request)) http.Handler {
+	return auth.Middleware(http.HandlerFunc(endpoint))
+}
+
+// adminPage is only for users with the admin role.
+func adminPage(w http.ResponseWriter, r *http.Request) {
+	claims, _ := jwtauth.ClaimsFromContext(r.Context())
+	fmt.Fprintf(w, "Hello Admin %s", claims["sub"])
+	fmt.Println("Endpoint Hit: adminPage")
 }
 
-func handleRequests() {
-	http.Handle("/", isAuthorized(homePage))
+func handleRequests(auth *jwtauth.Authenticator) {
+	http.Handle("/", isAuthorized(auth, homePage))
+	admin := jwtauth.Require(jwtauth.HasRole("admin"))(http.HandlerFunc(adminPage))
+	http.Handle("/admin", isAuthorized(auth, admin.ServeHTTP))
 	log.Fatal(http.ListenAndServe(":9000", nil))
 }
 
 func main() {
-	handleRequests()
+	flag.Parse()
+	handleRequests(newAuthenticator(*jwksURL))
 }
\ No newline at end of file
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestIsAuthorized(t *testing.T) {
+	hit := false
+	handler := isAuthorized(newAuthenticator(""), func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	})
+	sign := func(key []byte, claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	claims := func(exp time.Duration, aud string) jwt.MapClaims {
+		return jwt.MapClaims{"sub": "elliot", "iss": "http://localhost:9001", "aud": aud,
+			"exp": time.Now().Add(exp).Unix()}
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"valid token", sign(mySigningKey, claims(time.Minute, "http://localhost:9000")), http.StatusOK},
+		{"no token", "", http.StatusUnauthorized},
+		{"garbage", "not.a.token", http.StatusUnauthorized},
+		{"wrong key", sign([]byte("guess"), claims(time.Minute, "http://localhost:9000")), http.StatusUnauthorized},
+		{"expired", sign(mySigningKey, claims(-time.Minute, "http://localhost:9000")), http.StatusUnauthorized},
+		{"other audience", sign(mySigningKey, claims(time.Minute, "http://localhost:9002")), http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		hit = false
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.token != "" {
+			r.Header.Set("Token", test.token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("For %s expected status %d got %d", test.name, test.status, w.Code)
+		}
+		// A token failing to parse used to reach homePage anyway.
+		if hit != (test.status == http.StatusOK) {
+			t.Errorf("For %s expected the endpoint hit %t", test.name, !hit)
+		}
+	}
+}
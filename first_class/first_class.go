@@ -1,12 +1,15 @@
 package first_class
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 // user defined function type
 type add func(a int, b int) int
 
-func simple(a func(a, b int) int) {
-	fmt.Println(a(60, 7))
+func simple(w io.Writer, a func(a, b int) int) {
+	fmt.Fprintln(w, a(60, 7))
 }
 
 // this function returns another function(func(a, b int) int)
@@ -51,35 +54,35 @@ func iMap(integerSlice []int, multiplierFunction func(int) int) []int {
 	return returnedSlice
 }
 
-func RunFirstClassFunctions() {
-	fmt.Printf("\nBeginning of introduction to first class functions...\n")
+func RunFirstClassFunctions(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction to first class functions...\n")
 	/*
 	A language which supports first class functions allows functions to be assigned to variables, passed as arguments
 	to other functions and returned from other functions. Go has support for first class functions.
 	*/
-	fmt.Printf("\nBeginning of anonymous functions...\n")
+	fmt.Fprintf(w, "\nBeginning of anonymous functions...\n")
 	/*
 	Let's start with a simple example which assigns a function to a variable.
 	These kind of functions like below are called anonymous functions since they do not have a name.
 	*/
 	a := func() {
-		fmt.Println("hello world first first class function")
+		fmt.Fprintln(w, "hello world first first class function")
 	}
 	a()
-	fmt.Printf("%T\n", a)
+	fmt.Fprintf(w, "%T\n", a)
 	/*
 	The only way to call this function is using the variable a.
 	It is also possible to call a anonymous function without assigning it to a variable.
 	*/
 	func() {
-		fmt.Println("hello world second first class function")
+		fmt.Fprintln(w, "hello world second first class function")
 	}()
 	// It is also possible to pass arguments to anonymous functions just like any other function.
 	func(n string){
-		fmt.Println("Welcome", n)
+		fmt.Fprintln(w, "Welcome", n)
 	}("Gophers")
 
-	fmt.Printf("\nBeginning of user defined function types...\n")
+	fmt.Fprintf(w, "\nBeginning of user defined function types...\n")
 	/*
 	Just like we define our own struct types, it is possible to define our own function types:
 		type add func(a int, b int) int
@@ -90,9 +93,9 @@ func RunFirstClassFunctions() {
 		return a + b
 	}
 	s := b(5, 6)
-	fmt.Println("Sum", s)
+	fmt.Fprintln(w, "Sum", s)
 
-	fmt.Printf("\nBeginning of higher-order functions...\n")
+	fmt.Fprintf(w, "\nBeginning of higher-order functions...\n")
 	/*
 	The definition of Higher-order function from wiki is a function which does at least one of the following:
 		1- takes one or more functions as arguments
@@ -108,15 +111,15 @@ func RunFirstClassFunctions() {
 	f := func(a, b int) int {
 		return a + b
 	}
-	simple(f)
+	simple(w, f)
 	/*
 	2- Returning functions from other functions
 	Now let's rewrite the program above and return a function from the simple2 function.
 	*/
 	d := simple2()
-	fmt.Println(d(60, 7))
+	fmt.Fprintln(w, d(60, 7))
 
-	fmt.Printf("\nBeginning of Closures...\n")
+	fmt.Fprintf(w, "\nBeginning of Closures...\n")
 	/*
 	Closures are a special case of anonymous functions. Closures are anonymous functions which access the variables
 	defined outside the body of the function.
@@ -124,19 +127,19 @@ func RunFirstClassFunctions() {
 	e := 5
 	// closures are special case of anonymous functions.
 	func() {
-		fmt.Println("e = ", e)
+		fmt.Fprintln(w, "e = ", e)
 	}()
 
-	fmt.Printf("\nBeginning of Closure example...\n")
+	fmt.Fprintf(w, "\nBeginning of Closure example...\n")
 	/*
 	Every closure is bound to its own surrounding variable. Let's understand what this means by using a simple example.
 	*/
 	j := appendStr()
 	k := appendStr()
-	fmt.Println(j("World"))
-	fmt.Println(k("Everyone"))
-	fmt.Println(j("Gopher"))
-	fmt.Println(k("!"))
+	fmt.Fprintln(w, j("World"))
+	fmt.Fprintln(w, k("Everyone"))
+	fmt.Fprintln(w, j("Gopher"))
+	fmt.Fprintln(w, k("!"))
 	/*
 	In the program above, the function appendStr returns a closure. This closure is bound to the variable t. Let's
 	understand what this means.
@@ -148,7 +151,7 @@ func RunFirstClassFunctions() {
 	body of the function.
 	 */
 
-	fmt.Printf("\nBeginning of practical use of first class functions example 1...\n")
+	fmt.Fprintf(w, "\nBeginning of practical use of first class functions example 1...\n")
 	s1 := student{
 		firstName: "Naveen",
 		lastName:  "Ramanathan",
@@ -168,9 +171,9 @@ func RunFirstClassFunctions() {
 		}
 		return false
 	})
-	fmt.Println(filteredStudents)
+	fmt.Fprintln(w, filteredStudents)
 
-	fmt.Printf("\nBeginning of practical use of first class functions example 2...\n")
+	fmt.Fprintf(w, "\nBeginning of practical use of first class functions example 2...\n")
 	/*
 	This program will perform the same operations on each element of a slice and return the result. For example if
 	we want to multiply all integers in a slice by 5 and return the output, it can be easily done using first class
@@ -182,5 +185,5 @@ func RunFirstClassFunctions() {
 	returnedSlice := iMap(integerSlice, func(i int) int {
 		return i * 5
 	})
-	fmt.Println(returnedSlice)
+	fmt.Fprintln(w, returnedSlice)
 }
\ No newline at end of file
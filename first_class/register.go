@@ -0,0 +1,7 @@
+package first_class
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("first_class", "first_class_functions", RunFirstClassFunctions)
+}
@@ -1,6 +1,9 @@
 package functions
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 /*
 If consecutive parameters are of the same type, we can avoid writing the type each time and it is enough to be
@@ -31,8 +34,8 @@ func rectPropsNamedReturn(length, width float64) (area, perimeter float64) {
 	return // no explicit return value because the function knows what to return, but you can return with return values if you like
 }
 
-func RunFunctions() {
-	fmt.Printf("\nBeginning of introduction to functions...\n")
+func RunFunctions(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction to functions...\n")
 	/*
 	A function is a block of code that performs a specific task. A function takes a input, performs some calculations on
 	the input and generates a output.
@@ -43,29 +46,29 @@ func RunFunctions() {
 	*/
 	price, no := 90, 6
 	totalPrice := calculateBill(price, no)
-	fmt.Println("Total price is", totalPrice)
+	fmt.Fprintln(w, "Total price is", totalPrice)
 
-	fmt.Printf("\nBeginning of multiple return values...\n")
+	fmt.Fprintf(w, "\nBeginning of multiple return values...\n")
 	/*
 	It is possible to return multiple values from a function.
 	 */
 	area, perimeter := rectProps(10.8, 5.6)
-	fmt.Printf("Area %.2f Perimeter %.2f\n", area, perimeter)
+	fmt.Fprintf(w, "Area %.2f Perimeter %.2f\n", area, perimeter)
 
-	fmt.Printf("\nBeginning of named return values...\n")
+	fmt.Fprintf(w, "\nBeginning of named return values...\n")
 	/*
 	It is possible to return named values from a function. If a return value is named, it can be considered as being
 	declared as a variable in the first line of the function.
 	 */
 	area2, perimeter2 := rectPropsNamedReturn(10.8, 5.6)
-	fmt.Printf("Area %.2f Perimeter %.2f\n", area2, perimeter2)
+	fmt.Fprintf(w, "Area %.2f Perimeter %.2f\n", area2, perimeter2)
 
-	fmt.Printf("\nBeginning of blank identifier...\n")
+	fmt.Fprintf(w, "\nBeginning of blank identifier...\n")
 	/*
 	_ is know as the blank identifier in Go. It can be used in place of any value of any type.
 	The rectProps function returns the area and perimeter of the rectangle. What if we only need the area and want to
 	discard the perimeter. This is where _ is of use.
 	*/
 	area3, _ := rectProps(10.8, 5.6) // perimeter is discarded
-	fmt.Printf("Area %f ", area3)
+	fmt.Fprintf(w, "Area %f ", area3)
 }
\ No newline at end of file
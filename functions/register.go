@@ -0,0 +1,7 @@
+package functions
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("functions", "functions", RunFunctions)
+}
@@ -1,6 +1,6 @@
 module golang-tutorial
 
-go 1.12
+go 1.14
 
 require (
 	github.com/alexedwards/scs/v2 v2.3.0
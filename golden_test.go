@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang-tutorial/lessons"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// nondeterministic lists the lessons whose output can't be compared against a golden file, with the reason why.
+var nondeterministic = map[string]string{
+	"concurrency.buffered_pools":        "a goroutine prints while the main goroutine reads from it",
+	"concurrency.worker_pools_demo":     "workers pick up and finish jobs in no particular order",
+	"concurrency.select":                "chooses at random between channels that are ready at the same time",
+	"concurrency.mutexes":               "demonstrates a race condition on purpose",
+	"defers.defers":                     "prints from goroutines running at the same time",
+	"error_handling.error_handling":     "does a DNS lookup whose error depends on the network",
+	"error_handling.panic_and_recover2": "prints a stack trace",
+	"maps.maps":                         "ranges over a map",
+	"pointers.pointers":                 "prints memory addresses",
+}
+
+func TestLessonsGolden(t *testing.T) {
+	for _, l := range lessons.All() {
+		l := l
+		t.Run(l.FullName(), func(t *testing.T) {
+			if reason, ok := nondeterministic[l.FullName()]; ok {
+				t.Skip(reason)
+			}
+			var buf bytes.Buffer
+			res := lessons.Run(l, &buf, lessons.Options{TestMode: true, Seed: 1})
+			if res.Err != nil {
+				t.Fatal(res.Err)
+			}
+
+			golden := filepath.Join("testdata", "golden", l.FullName()+".golden")
+			if *update {
+				if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%s, run go test -update to create it", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output of %s doesn't match %s\ngot:\n%s\nwant:\n%s", l.FullName(), golden, buf.Bytes(), want)
+			}
+		})
+	}
+}
@@ -2,36 +2,38 @@ package goroutines
 
 import (
 	"fmt"
+	"golang-tutorial/lessons"
+	"io"
 	"time"
 )
 
-func hello() {
-	fmt.Println("Hello world goroutine")
+func hello(w io.Writer) {
+	fmt.Fprintln(w, "Hello world goroutine")
 }
 
-func numbers() {
+func numbers(w io.Writer) {
 	for i := 1; i <= 5; i++ {
-		time.Sleep(250 * time.Millisecond)
-		fmt.Printf("%d ", i)
+		lessons.Sleep(250 * time.Millisecond)
+		fmt.Fprintf(w, "%d ", i)
 	}
 }
 
-func alphabets() {
+func alphabets(w io.Writer) {
 	for i := 'a'; i <= 'e'; i++ {
-		time.Sleep(400 * time.Millisecond)
-		fmt.Printf("%c ", i)
+		lessons.Sleep(400 * time.Millisecond)
+		fmt.Fprintf(w, "%c ", i)
 	}
 }
 
-func RunGoroutines() {
-	fmt.Printf("\nBeginning of introduction to Goroutines...\n")
+func RunGoroutines(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction to Goroutines...\n")
 	/*
 	Goroutines are functions or methods that run concurrently with other functions or methods. Goroutines can be thought
 	of as light weight threads. The cost of creating a Goroutine is tiny when compared to a thread. Hence its common for
 	Go applications to have thousands of Goroutines running concurrently.
 	*/
 
-	fmt.Printf("\nBeginning of advantages of Goroutines over threads...\n")
+	fmt.Fprintf(w, "\nBeginning of advantages of Goroutines over threads...\n")
 	/*
 	- Goroutines are extremely cheap when compared to threads. They are only a few kb in stack size and the stack can
 	grow and shrink according to needs of the application whereas in the case of threads the stack size has to be
@@ -44,7 +46,7 @@ func RunGoroutines() {
 	shared memory using Goroutines. Channels can be thought of as a pipe using which Goroutines communicate.
 	*/
 
-	fmt.Printf("\nBeginning of starting Goroutines...\n")
+	fmt.Fprintf(w, "\nBeginning of starting Goroutines...\n")
 	/*
 	Prefix the function or method call with the keyword go and you will have a new Goroutine running concurrently.
 	The main function runs in its own Goroutine and its called the main Goroutine.
@@ -54,18 +56,18 @@ func RunGoroutines() {
 	The main Goroutine should be running for any other Goroutines to run. If the main Goroutine terminates then the
 	program will be terminated and no other Goroutine will run.
 	 */
-	go hello()
+	go hello(w)
 	/*
 	This way of using sleep in the main Goroutine to wait for other Goroutines to finish their execution is a hack we
 	are using to understand how Goroutines work. Channels can be used to block the main Goroutine until all other
 	Goroutines finish their execution.
 	*/
-	time.Sleep(1 * time.Second)
-	fmt.Println("main goroutine")
+	lessons.Sleep(1 * time.Second)
+	fmt.Fprintln(w, "main goroutine")
 
-	fmt.Printf("\nBeginning of starting multiple Goroutines...\n")
-	go numbers()
-	go alphabets()
-	time.Sleep(3000 * time.Millisecond)
-	fmt.Println("main goroutine terminated")
+	fmt.Fprintf(w, "\nBeginning of starting multiple Goroutines...\n")
+	go numbers(w)
+	go alphabets(w)
+	lessons.Sleep(3000 * time.Millisecond)
+	fmt.Fprintln(w, "main goroutine terminated")
 }
\ No newline at end of file
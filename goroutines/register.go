@@ -0,0 +1,7 @@
+package goroutines
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("goroutines", "goroutines", RunGoroutines)
+}
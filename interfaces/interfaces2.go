@@ -1,9 +1,12 @@
 package interfaces
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 type describer interface {
-	describe()
+	describe(w io.Writer)
 }
 
 type person struct {
@@ -11,8 +14,8 @@ type person struct {
 	age int
 }
 
-func (p person) describe() {
-	fmt.Printf("%s is %d years old\n", p.name, p.age)
+func (p person) describe(w io.Writer) {
+	fmt.Fprintf(w, "%s is %d years old\n", p.name, p.age)
 }
 
 type employee struct {
@@ -30,12 +33,12 @@ type address struct {
 }
 
 //https://de.spankbang.com/3ukvk/video/gata
-func (a *address) describe() {
-	fmt.Printf("State %s Country %s\n", a.state, a.country)
+func (a *address) describe(w io.Writer) {
+	fmt.Fprintf(w, "State %s Country %s\n", a.state, a.country)
 }
 
 type salaryCalculator interface {
-	displaySalary()
+	displaySalary(w io.Writer)
 }
 
 type leaveCalculator interface {
@@ -47,16 +50,16 @@ type employeeOperations interface {
 	leaveCalculator
 }
 
-func (e employee) displaySalary() {
-	fmt.Printf("%s %s has salary $%d\n", e.firstName, e.lastName, e.basicPay + e.pf)
+func (e employee) displaySalary(w io.Writer) {
+	fmt.Fprintf(w, "%s %s has salary $%d\n", e.firstName, e.lastName, e.basicPay + e.pf)
 }
 
 func (e employee) calculateLeavesLeft() int {
 	return e.totalLeaves - e.leavesTaken
 }
 
-func RunInterfaces2() {
-	fmt.Printf("\nBeginning of implementing interfaces using pointer receivers vs value receivers...\n")
+func RunInterfaces2(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of implementing interfaces using pointer receivers vs value receivers...\n")
 	/*
 	All the example interfaces we discussed in part 1 were implemented using value receivers. It is also possible to
 	implement interfaces using pointer receivers. There is a subtlety to be noted while implementing interfaces using
@@ -65,12 +68,12 @@ func RunInterfaces2() {
 	var d1 describer
 	p1 := person{"Sam", 25}
 	d1 = p1
-	d1.describe()
-	fmt.Printf("p1 has type %T value %v\n", p1, p1)
+	d1.describe(w)
+	fmt.Fprintf(w, "p1 has type %T value %v\n", p1, p1)
 	p2 := person{"James", 32}
 	d1 = &p2
-	d1.describe()
-	fmt.Printf("p2 has type %T value %v\n", p2, p2)
+	d1.describe(w)
+	fmt.Fprintf(w, "p2 has type %T value %v\n", p2, p2)
 	/*
 	As we have already learnt during our discussion about methods, methods with value receivers accept both pointer and
 	value receivers. It is legal to call a value method on anything which is a value or whose value can be dereferenced.
@@ -89,14 +92,14 @@ func RunInterfaces2() {
 	*/
 	// d2 = a
 	d2 = &a
-	d2.describe()
+	d2.describe(w)
 	if d2 == nil {
-		fmt.Printf("d2 is nil and has type %T value %v\n", d2, d2)
+		fmt.Fprintf(w, "d2 is nil and has type %T value %v\n", d2, d2)
 	} else {
-		fmt.Printf("d2 is not nil and has type %T value %v\n", d2, d2)
+		fmt.Fprintf(w, "d2 is not nil and has type %T value %v\n", d2, d2)
 	}
 
-	fmt.Printf("\nBeginning of implementing multiple interfaces...\n")
+	fmt.Fprintf(w, "\nBeginning of implementing multiple interfaces...\n")
 	/*
 	A type can implement more than one interface. employee structure both implements salaryCalculator and leaveCAlculator
 	interfaces.
@@ -109,20 +112,20 @@ func RunInterfaces2() {
 		totalLeaves: 30,
 		leavesTaken: 5,
 	}
-	e.displaySalary()
+	e.displaySalary(w)
 	/*
 	Below declaration is possible since e which of type Employee implements SalaryCalculator interface.
 	 */
 	var s salaryCalculator = e
-	s.displaySalary()
-	fmt.Printf("%d leaves left\n", e.calculateLeavesLeft())
+	s.displaySalary(w)
+	fmt.Fprintf(w, "%d leaves left\n", e.calculateLeavesLeft())
 	/*
 	Below declaration is possible since e which of type Employee implements LeaveCalculator interface.
 	*/
 	var l leaveCalculator = e
-	fmt.Printf("%d leaves left\n", l.calculateLeavesLeft())
+	fmt.Fprintf(w, "%d leaves left\n", l.calculateLeavesLeft())
 
-	fmt.Printf("\nBeginning of embedded interfaces...\n")
+	fmt.Fprintf(w, "\nBeginning of embedded interfaces...\n")
 	/*
 	Although go does not offer inheritance, it is possible to create a new interfaces by embedding other interfaces.
 	 */
@@ -132,8 +135,8 @@ func RunInterfaces2() {
 	Below declaration is possible since e2 which of type Employee implements EmployeeOperations interface which includes
 	both SalaryCalculator and LeaveCalculator interfaces.
 	*/
-	empOp.displaySalary()
-	fmt.Printf("%d leaves left", empOp.calculateLeavesLeft())
+	empOp.displaySalary(w)
+	fmt.Fprintf(w, "%d leaves left", empOp.calculateLeavesLeft())
 	/*
 	EmployeeOperations interface of the program above is created by embedding SalaryCalculator and LeaveCalculator interfaces.
 	Any type is said to implement EmployeeOperations interface if it provides method definitions for the methods present
@@ -142,7 +145,7 @@ func RunInterfaces2() {
 	CalculateLeavesLeft methods.
 	 */
 
-	fmt.Printf("\nBeginning of zero value of interface...\n")
+	fmt.Fprintf(w, "\nBeginning of zero value of interface...\n")
 	/*
 	The zero value of a interface is nil. A nil interface has both its underlying value and as well as concrete type as nil.
 	If we try to call a method on the nil interface, the program will panic since the nil interface neither has a underlying
@@ -153,10 +156,10 @@ func RunInterfaces2() {
 	If we uncomment the below line, program will panic like that:
 	panic: runtime error: invalid memory address or nil pointer dereference
 	*/
-	// nilInterface.describe()
+	// nilInterface.describe(w)
 	if nilInterface == nil {
-		fmt.Printf("nilInterface is nil and has type %T value %v\n", nilInterface, nilInterface)
+		fmt.Fprintf(w, "nilInterface is nil and has type %T value %v\n", nilInterface, nilInterface)
 	} else {
-		nilInterface.describe()
+		nilInterface.describe(w)
 	}
 }
\ No newline at end of file
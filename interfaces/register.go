@@ -0,0 +1,8 @@
+package interfaces
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("interfaces", "interfaces1", RunInterfaces1)
+	lessons.Register("interfaces", "interfaces2", RunInterfaces2)
+}
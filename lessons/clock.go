@@ -0,0 +1,217 @@
+package lessons
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+Clock is the source of time for lessons. Lessons call Now, Since, Sleep and After from this package instead of the time
+package, so the runner can swap the real clock for a virtual one in test mode.
+*/
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+var (
+	clockMu sync.RWMutex
+	clock   Clock = realClock{}
+)
+
+func currentClock() Clock {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return clock
+}
+
+func setClock(c Clock) {
+	clockMu.Lock()
+	clock = c
+	clockMu.Unlock()
+}
+
+// Now returns the current time of the clock lessons are running against.
+func Now() time.Time {
+	return currentClock().Now()
+}
+
+// Since returns the time elapsed since t on the clock lessons are running against.
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// Sleep pauses the calling goroutine for at least d on the clock lessons are running against.
+func Sleep(d time.Duration) {
+	currentClock().Sleep(d)
+}
+
+// After sends the time on the returned channel once d has passed on the clock lessons are running against.
+func After(d time.Duration) <-chan time.Time {
+	return currentClock().After(d)
+}
+
+/*
+settleTime is how long the virtual clock waits, in real time, for goroutines woken up by the last tick to block
+again before it moves on to the next tick. See virtualClock for what this doesn't guarantee.
+*/
+const settleTime = time.Millisecond
+
+// virtualEpoch is the time a virtual clock starts at, so Now is reproducible between runs too.
+var virtualEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// sleeper is a goroutine waiting in Sleep or, if fire is set, a timer set by After.
+type sleeper struct {
+	until time.Time
+	seq   int
+	wake  chan struct{}
+	fire  chan time.Time
+}
+
+/*
+virtualClock is a clock whose time only moves when goroutines sleep. Sleep doesn't wait in real time; it queues the
+caller and once no goroutine has gone to sleep for a settle period, time jumps straight to the earliest wake up time
+and releases that one goroutine. Goroutines due at the same time are released one settle period apart, in the order
+they went to sleep. Sleeps therefore keep their relative order, which is all the lessons rely on, while a lesson that
+sleeps for seconds finishes in milliseconds.
+
+The settle period is a heuristic, not an account of the goroutines a lesson runs: the clock can't tell a goroutine
+about to call Sleep from one blocked on a channel. Order is only guaranteed between sleeps that are queued before
+time moves past them. Those are the sleeps of a single goroutine and the timers After queues right away, since
+neither waits to be scheduled. A goroutine that takes longer than settleTime of real time to be scheduled and reach
+its Sleep, say on a loaded machine, may find time already moved on and wake up later than it should have.
+*/
+type virtualClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	seq      int
+	sleepers []*sleeper
+	kick     chan struct{}
+	stop     chan struct{}
+}
+
+func newVirtualClock() *virtualClock {
+	c := &virtualClock{
+		now:  virtualEpoch,
+		kick: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+func (c *virtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *virtualClock) Sleep(d time.Duration) {
+	s := &sleeper{wake: make(chan struct{})}
+	c.queue(s, d)
+	<-s.wake
+}
+
+/*
+After queues the timer before it returns and the clock sends the time itself, so the timer keeps its order with the
+sleeps of the caller and has fired by the time anything due after it wakes up.
+*/
+func (c *virtualClock) After(d time.Duration) <-chan time.Time {
+	s := &sleeper{wake: make(chan struct{}), fire: make(chan time.Time, 1)}
+	c.queue(s, d)
+	return s.fire
+}
+
+// queue adds s to the sleepers of the clock, due after d, and makes sure the clock runs to wake it up.
+func (c *virtualClock) queue(s *sleeper, d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	c.mu.Lock()
+	c.seq++
+	s.until, s.seq = c.now.Add(d), c.seq
+	c.sleepers = append(c.sleepers, s)
+	sort.Slice(c.sleepers, func(i, j int) bool {
+		if c.sleepers[i].until.Equal(c.sleepers[j].until) {
+			return c.sleepers[i].seq < c.sleepers[j].seq
+		}
+		return c.sleepers[i].until.Before(c.sleepers[j].until)
+	})
+	c.mu.Unlock()
+
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
+
+/*
+Stop freezes the clock. Goroutines a lesson left sleeping when it returned stay asleep, so they can't write to the
+lesson's output after the runner has moved on.
+*/
+func (c *virtualClock) Stop() {
+	close(c.stop)
+}
+
+func (c *virtualClock) run() {
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-c.kick:
+		}
+		for c.settle() {
+			c.mu.Lock()
+			if len(c.sleepers) == 0 {
+				c.mu.Unlock()
+				break
+			}
+			s := c.sleepers[0]
+			c.sleepers = c.sleepers[1:]
+			c.now = s.until
+			c.mu.Unlock()
+			if s.fire != nil {
+				s.fire <- s.until
+			}
+			close(s.wake)
+		}
+	}
+}
+
+/*
+settle waits until no new goroutine has gone to sleep for a whole settle period. It returns false if the clock was
+stopped in the meantime.
+*/
+func (c *virtualClock) settle() bool {
+	last := -1
+	for {
+		select {
+		case <-c.stop:
+			return false
+		case <-time.After(settleTime):
+		}
+		c.mu.Lock()
+		seq := c.seq
+		c.mu.Unlock()
+		if seq == last {
+			return true
+		}
+		last = seq
+	}
+}
@@ -0,0 +1,109 @@
+package lessons
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestVirtualClockSleep(t *testing.T) {
+	c := newVirtualClock()
+	defer c.Stop()
+
+	if !c.Now().Equal(virtualEpoch) {
+		t.Fatalf("clock starts at %v, want %v", c.Now(), virtualEpoch)
+	}
+	start := time.Now()
+	for _, d := range []time.Duration{time.Hour, 0, -time.Minute, 10 * time.Second} {
+		before := c.Now()
+		c.Sleep(d)
+		want := before
+		if d > 0 {
+			want = before.Add(d)
+		}
+		if !c.Now().Equal(want) {
+			t.Errorf("after Sleep(%v) clock is at %v, want %v", d, c.Now(), want)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("sleeping for an hour took %v of real time", elapsed)
+	}
+}
+
+func TestVirtualClockAfter(t *testing.T) {
+	c := newVirtualClock()
+	defer c.Stop()
+
+	// Timers fire in the order they are due, not the order they were set, and each at its own time.
+	late := c.After(3 * time.Second)
+	early := c.After(time.Second)
+	alsoEarly := c.After(time.Second)
+
+	c.Sleep(2 * time.Second)
+	for name, ch := range map[string]<-chan time.Time{"early": early, "alsoEarly": alsoEarly} {
+		select {
+		case at := <-ch:
+			if want := virtualEpoch.Add(time.Second); !at.Equal(want) {
+				t.Errorf("%s timer fired at %v, want %v", name, at, want)
+			}
+		default:
+			t.Errorf("%s timer due after 1s hasn't fired at %v", name, c.Now())
+		}
+	}
+	select {
+	case at := <-late:
+		t.Errorf("timer due after 3s fired at %v, before the sleep until 2s ended", at)
+	default:
+	}
+
+	c.Sleep(2 * time.Second)
+	select {
+	case at := <-late:
+		if want := virtualEpoch.Add(3 * time.Second); !at.Equal(want) {
+			t.Errorf("late timer fired at %v, want %v", at, want)
+		}
+	default:
+		t.Errorf("timer due after 3s hasn't fired at %v", c.Now())
+	}
+	if want := virtualEpoch.Add(4 * time.Second); !c.Now().Equal(want) {
+		t.Errorf("clock is at %v, want %v", c.Now(), want)
+	}
+}
+
+func TestVirtualClockStop(t *testing.T) {
+	c := newVirtualClock()
+	c.Sleep(time.Second)
+	c.Stop()
+
+	ch := c.After(time.Second)
+	select {
+	case at := <-ch:
+		t.Errorf("timer fired at %v after the clock was stopped", at)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if want := virtualEpoch.Add(time.Second); !c.Now().Equal(want) {
+		t.Errorf("stopped clock moved to %v, want %v", c.Now(), want)
+	}
+}
+
+func TestRunRestoresRealClock(t *testing.T) {
+	var during time.Time
+	l := Lesson{Package: "clock", Name: "test", Run: func(w io.Writer) {
+		Sleep(time.Minute)
+		during = Now()
+	}}
+	res := Run(l, ioutil.Discard, Options{TestMode: true, Seed: 1})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := virtualEpoch.Add(time.Minute); !during.Equal(want) {
+		t.Errorf("lesson saw time %v, want %v", during, want)
+	}
+	if res.Duration > 10*time.Second {
+		t.Errorf("a one minute sleep in test mode took %v", res.Duration)
+	}
+	if _, ok := currentClock().(realClock); !ok {
+		t.Errorf("clock after Run is %T, want the real clock", currentClock())
+	}
+}
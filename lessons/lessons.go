@@ -0,0 +1,158 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Lesson is a single runnable section of the tutorial. Every lesson package registers its Run* functions from an init
+function, so the lesson runner in main.go only has to import the package to make its lessons available. Run writes
+everything the lesson prints to the given writer instead of straight to stdout, which is what lets the golden tests
+capture it.
+*/
+type Lesson struct {
+	Package string
+	Name    string
+	Run     func(w io.Writer)
+}
+
+// FullName returns the name a lesson is addressed with on the command line, e.g. "concurrency.select".
+func (l Lesson) FullName() string {
+	return l.Package + "." + l.Name
+}
+
+// Result is the outcome of running a single lesson.
+type Result struct {
+	Lesson   Lesson
+	Duration time.Duration
+	Err      error
+}
+
+var registry []Lesson
+
+/*
+Register makes a lesson available to the runner. It is meant to be called from the init function of a lesson package
+and panics if the same lesson is registered twice, the same way database/sql.Register does for drivers.
+*/
+func Register(pkg, name string, run func(w io.Writer)) {
+	if run == nil {
+		panic("lessons: Register run func is nil")
+	}
+	l := Lesson{Package: pkg, Name: name, Run: run}
+	for _, r := range registry {
+		if r.FullName() == l.FullName() {
+			panic("lessons: Register called twice for lesson " + l.FullName())
+		}
+	}
+	registry = append(registry, l)
+}
+
+/*
+All returns every registered lesson ordered by package. Lessons of the same package keep the order they were
+registered in, which is the order they are meant to be read in.
+*/
+func All() []Lesson {
+	all := make([]Lesson, len(registry))
+	copy(all, registry)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Package < all[j].Package
+	})
+	return all
+}
+
+// Package returns the lessons registered by the given package, or an error if the package has none.
+func Package(pkg string) ([]Lesson, error) {
+	var ls []Lesson
+	for _, l := range All() {
+		if l.Package == pkg {
+			ls = append(ls, l)
+		}
+	}
+	if len(ls) == 0 {
+		return nil, fmt.Errorf("no lessons registered for package %q", pkg)
+	}
+	return ls, nil
+}
+
+/*
+Lookup finds a lesson by its full name. A bare lesson name such as "slices" is accepted too, as long as only one
+package registered a lesson with that name.
+*/
+func Lookup(name string) (Lesson, error) {
+	var matches []Lesson
+	for _, l := range All() {
+		if l.FullName() == name {
+			return l, nil
+		}
+		if l.Name == name {
+			matches = append(matches, l)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Lesson{}, fmt.Errorf("unknown lesson %q", name)
+	case 1:
+		return matches[0], nil
+	}
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.FullName())
+	}
+	return Lesson{}, fmt.Errorf("lesson name %q is ambiguous, use one of: %s", name, strings.Join(names, ", "))
+}
+
+// Options changes how Run executes a lesson.
+type Options struct {
+	// TestMode runs the lesson against a virtual clock, so its sleeps take no real time, and against a random
+	// source seeded with Seed, so its output is the same on every run.
+	TestMode bool
+	Seed     int64
+}
+
+/*
+Run executes a lesson, writing its output to w, and reports how long it took in real time. A panic escaping the
+lesson is recovered and turned into the result's error so that one broken lesson doesn't stop the others from
+running.
+*/
+func Run(l Lesson, w io.Writer, opts Options) (res Result) {
+	res.Lesson = l
+	if opts.TestMode {
+		vc := newVirtualClock()
+		setClock(vc)
+		seed(opts.Seed)
+		defer func() {
+			vc.Stop()
+			setClock(realClock{})
+			seed(time.Now().UnixNano())
+		}()
+	}
+	start := time.Now()
+	defer func() {
+		res.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			res.Err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	l.Run(&syncWriter{w: w})
+	return res
+}
+
+/*
+syncWriter serializes writes to the underlying writer. Lessons print from many goroutines at once, which is fine for
+os.Stdout but not for a buffer capturing their output.
+*/
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (sw *syncWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(p)
+}
@@ -0,0 +1,30 @@
+package lessons
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+/*
+rnd is the random source shared by all lessons. *rand.Rand is not safe for concurrent use and lessons draw numbers
+from many goroutines at once, hence the mutex.
+*/
+var rnd = struct {
+	sync.Mutex
+	r *rand.Rand
+}{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// Intn returns a random number in [0,n) from the random source lessons are running against.
+func Intn(n int) int {
+	rnd.Lock()
+	defer rnd.Unlock()
+	return rnd.r.Intn(n)
+}
+
+// seed resets the random source so the sequence of numbers lessons draw from it is reproducible.
+func seed(s int64) {
+	rnd.Lock()
+	rnd.r = rand.New(rand.NewSource(s))
+	rnd.Unlock()
+}
@@ -0,0 +1,7 @@
+package loops
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("loops", "loops", RunLoops)
+}
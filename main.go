@@ -1,40 +1,127 @@
 package main
 
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	_ "golang-tutorial/arrays"
+	_ "golang-tutorial/concurrency"
+	_ "golang-tutorial/conditionals"
+	_ "golang-tutorial/constants"
+	_ "golang-tutorial/defers"
+	_ "golang-tutorial/error_handling"
+	_ "golang-tutorial/first_class"
+	_ "golang-tutorial/functions"
+	_ "golang-tutorial/goroutines"
+	_ "golang-tutorial/interfaces"
+	"golang-tutorial/lessons"
+	_ "golang-tutorial/loops"
+	_ "golang-tutorial/maps"
+	_ "golang-tutorial/methods"
+	_ "golang-tutorial/oop"
+	_ "golang-tutorial/packages"
+	_ "golang-tutorial/pointers"
+	_ "golang-tutorial/reflection"
+	_ "golang-tutorial/slices"
+	_ "golang-tutorial/strings"
+	_ "golang-tutorial/structures"
+	_ "golang-tutorial/switch_statement"
+	_ "golang-tutorial/types"
+	_ "golang-tutorial/variables"
+	_ "golang-tutorial/variadic_functions"
+)
+
+/*
+Exit codes of the lesson runner. exitFailed is returned when at least one lesson panicked, exitUsage when the command
+line could not be understood.
+*/
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `Usage:
+	golang-tutorial list
+	golang-tutorial run [--test [--seed n]] <lesson>
+	golang-tutorial run [--test [--seed n]] --all
+	golang-tutorial run [--test [--seed n]] --package <package>
+`
+
 func main() {
-	//variables.RunVariables()
-	//types.RunTypes()
-	//constants.RunConstants()
-	//functions.RunFunctions()
-	//packages.RunPackages()
-	//conditionals.RunConditionals()
-	//loops.RunLoops()
-	//switch_statement.RunSwitchStatement()
-	//arrays.RunArrays()
-	//slices.RunSlices()
-	//variadic_functions.RunVariadicFunctions()
-	//maps.RunMaps()
-	//strings.RunStrings()
-	//pointers.RunPointers()
-	//structures.RunStructures()
-	//methods.RunMethods()
-	//interfaces.RunInterfaces1()
-	//interfaces.RunInterfaces2()
-	//concurrency.RunConcurrency()
-	//concurrency.RunGoroutines()
-	//concurrency.RunChannels()
-	//concurrency.RunBufferedPools()
-	//concurrency.RunWorkerPoolsDemo()
-	//concurrency.RunSelect()
-	//concurrency.RunMutexes()
-	//oop.RunClasses()
-	//oop.RunInheritance()
-	//oop.RunPolymorphism()
-	//defers.RunDefers()
-	//error_handling.RunErrorHandling()
-	//error_handling.RunPanicAndRecover()
-	//error_handling.RunPanicAndRecover2()
-	//first_class.RunFirstClassFunctions()
-	//reflection.RunReflection()
-	//exercises.ReadFiles()
-	//exercises.WriteFiles()
-}
\ No newline at end of file
+	os.Exit(runMain(os.Args[1:]))
+}
+
+func runMain(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "list":
+		return listLessons()
+	case "run":
+		return runLessons(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return exitOK
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+	return exitUsage
+}
+
+func listLessons() int {
+	for _, l := range lessons.All() {
+		fmt.Println(l.FullName())
+	}
+	return exitOK
+}
+
+func runLessons(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	all := fs.Bool("all", false, "run every registered lesson")
+	pkg := fs.String("package", "", "run every lesson of the given package")
+	testMode := fs.Bool("test", false, "run against a virtual clock and a seeded random source")
+	seed := fs.Int64("seed", 1, "seed of the random source in test mode")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	var ls []lessons.Lesson
+	switch {
+	case *all && *pkg == "" && fs.NArg() == 0:
+		ls = lessons.All()
+	case *pkg != "" && !*all && fs.NArg() == 0:
+		var err error
+		ls, err = lessons.Package(*pkg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	case !*all && *pkg == "" && fs.NArg() == 1:
+		l, err := lessons.Lookup(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		ls = append(ls, l)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	opts := lessons.Options{TestMode: *testMode, Seed: *seed}
+	code := exitOK
+	for _, l := range ls {
+		fmt.Printf("=== RUN   %s\n", l.FullName())
+		res := lessons.Run(l, os.Stdout, opts)
+		if res.Err != nil {
+			fmt.Printf("--- FAIL: %s (%.2fs)\n    %v\n", l.FullName(), res.Duration.Seconds(), res.Err)
+			code = exitFailed
+			continue
+		}
+		fmt.Printf("--- PASS: %s (%.2fs)\n", l.FullName(), res.Duration.Seconds())
+	}
+	return code
+}
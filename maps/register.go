@@ -0,0 +1,7 @@
+package maps
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("maps", "maps", RunMaps)
+}
@@ -2,6 +2,7 @@ package methods
 
 import (
 	"fmt"
+	"io"
 	"math"
 )
 
@@ -11,23 +12,23 @@ func (a myInt) add(b myInt) myInt {
 	return a + b
 }
 
-func area(r Rectangle) {
-	fmt.Printf("Area function result: %d\n", r.length * r.width)
+func area(w io.Writer, r Rectangle) {
+	fmt.Fprintf(w, "Area function result: %d\n", r.length * r.width)
 }
 
 /*
 This method can accept both pointer receiver and value receiver.
  */
-func (r Rectangle) area() {
-	fmt.Printf("Area Method result: %d\n", (r.length * r.width))
+func (r Rectangle) area(w io.Writer) {
+	fmt.Fprintf(w, "Area Method result: %d\n", (r.length * r.width))
 }
 
-func (r *Rectangle) perimeter() {
-	fmt.Println("perimeter method output: ", 2 * (r.length + r.width))
+func (r *Rectangle) perimeter(w io.Writer) {
+	fmt.Fprintln(w, "perimeter method output: ", 2 * (r.length + r.width))
 }
 
-func perimeter(r *Rectangle) {
-	fmt.Println("perimeter function output:", 2 * (r.length + r.width))
+func perimeter(w io.Writer, r *Rectangle) {
+	fmt.Fprintln(w, "perimeter function output:", 2 * (r.length + r.width))
 }
 
 type address struct {
@@ -35,8 +36,8 @@ type address struct {
 	state string
 }
 
-func (a address) fullAddress() {
-	fmt.Printf("Full address: %s, %s\n", a.city, a.state)
+func (a address) fullAddress(w io.Writer) {
+	fmt.Fprintf(w, "Full address: %s, %s\n", a.city, a.state)
 }
 
 type person struct {
@@ -71,13 +72,13 @@ func (c Circle) Area() float64 {
 }
 
 // void method, does not return anything
-func (e Employee) displaySalary() {
-	fmt.Printf("Salary of %s is %s%d\n", e.name, e.currency, e.salary)
+func (e Employee) displaySalary(w io.Writer) {
+	fmt.Fprintf(w, "Salary of %s is %s%d\n", e.name, e.currency, e.salary)
 }
 
 // void function, does not return anything
-func displaySalary(e Employee) {
-	fmt.Printf("Salary of %s is %s%d\n", e.name, e.currency, e.salary)
+func displaySalary(w io.Writer, e Employee) {
+	fmt.Fprintf(w, "Salary of %s is %s%d\n", e.name, e.currency, e.salary)
 }
 
 // method with value receiver
@@ -90,8 +91,8 @@ func (e *Employee) changeSalary(newSalary int) {
 	e.salary = newSalary
 }
 
-func RunMethods()  {
-	fmt.Printf("\nBeginning of introduction to methods...\n")
+func RunMethods(w io.Writer)  {
+	fmt.Fprintf(w, "\nBeginning of introduction to methods...\n")
 	/*
 	A method is just a function with a special receiver type between the func keyword and the method name. The receiver
 	can either be a struct type or non-struct type.
@@ -102,23 +103,23 @@ func RunMethods()  {
 	be accessed within the method.
 	*/
 
-	fmt.Printf("\nBeginning of sample methods...\n")
+	fmt.Fprintf(w, "\nBeginning of sample methods...\n")
 	emp1 := Employee{
 		name:     "Sam Adolf",
 		salary:   5000,
 		currency: "$",
 	}
-	emp1.displaySalary()
+	emp1.displaySalary(w)
 
-	fmt.Printf("\nBeginning of sample functions...\n")
+	fmt.Fprintf(w, "\nBeginning of sample functions...\n")
 	emp2 := Employee{
 		name:     "Sam Adolf",
 		salary:   5000,
 		currency: "$",
 	}
-	displaySalary(emp2)
+	displaySalary(w, emp2)
 
-	fmt.Printf("\nBeginning of methods vs functions...\n")
+	fmt.Fprintf(w, "\nBeginning of methods vs functions...\n")
 	/*
 	So why do we have methods when we can write the same program using functions. There are a couple of reasons for this.
 	Let's look at them one by one:
@@ -132,16 +133,16 @@ func RunMethods()  {
 		and Circle.
 	 */
 
-	fmt.Printf("\nBeginning of more on methods...\n")
+	fmt.Fprintf(w, "\nBeginning of more on methods...\n")
 	r := Rectangle{
 		length: 10,
 		width:  5,
 	}
-	fmt.Printf("Area of rectangle is %d\n", r.Area())
+	fmt.Fprintf(w, "Area of rectangle is %d\n", r.Area())
 	c := Circle{radius: 12}
-	fmt.Printf("Area of circle is %f\n", c.Area())
+	fmt.Fprintf(w, "Area of circle is %f\n", c.Area())
 
-	fmt.Printf("\nBeginning of pointer receivers vs value receivers...\n")
+	fmt.Fprintf(w, "\nBeginning of pointer receivers vs value receivers...\n")
 	/*
 	So far we have seen methods only with value receivers. It is possible to create methods with pointer receivers. The
 	difference between value and pointer receiver is, changes made inside a method with a pointer receiver is visible to
@@ -152,20 +153,20 @@ func RunMethods()  {
 		salary:   5000,
 		currency: "$",
 	}
-	fmt.Printf("Employee name before change: %s\n", emp3.name)
+	fmt.Fprintf(w, "Employee name before change: %s\n", emp3.name)
 	emp3.changeName("Michael Andrew")
-	fmt.Printf("Employee name after change: %s\n", emp3.name)
+	fmt.Fprintf(w, "Employee name after change: %s\n", emp3.name)
 
-	fmt.Printf("Employee salary before change: %d\n", emp3.salary)
+	fmt.Fprintf(w, "Employee salary before change: %d\n", emp3.salary)
 	(&emp3).changeSalary(10000)
-	fmt.Printf("Employee salary after change: %d\n", emp3.salary)
+	fmt.Fprintf(w, "Employee salary after change: %d\n", emp3.salary)
 	/*
 	calling the method with pointer receiver with & is not needed. Same functionality can be done with below line.
 	 */
 	emp3.changeSalary(15000)
-	fmt.Printf("Employee salary after change: %d\n", emp3.salary)
+	fmt.Fprintf(w, "Employee salary after change: %d\n", emp3.salary)
 
-	fmt.Printf("\nBeginning of when to use pointer receiver and when to use value receiver...\n")
+	fmt.Fprintf(w, "\nBeginning of when to use pointer receiver and when to use value receiver...\n")
 	/*
 	Generally, pointer receivers can be used when changes made to the receiver inside the method should be visible to
 	the caller.
@@ -176,7 +177,7 @@ func RunMethods()  {
 	In all other situations, value receivers can be used.
 	*/
 
-	fmt.Printf("\nBeginning of methods of anonymous struct fields...\n")
+	fmt.Fprintf(w, "\nBeginning of methods of anonymous struct fields...\n")
 	/*
 	Methods belonging to anonymous fields of a struct can be called as if they belong to the structure where the
 	anonymous field is defined. This behavior looks like promoted fields.
@@ -189,9 +190,9 @@ func RunMethods()  {
 			state: "California",
 		},
 	}
-	p.fullAddress()
+	p.fullAddress(w)
 
-	fmt.Printf("\nBeginning of value receivers in methods vs value arguments in functions...\n")
+	fmt.Fprintf(w, "\nBeginning of value receivers in methods vs value arguments in functions...\n")
 	/*
 	When a function has a value argument, it will accept only a value argument.
 	When a method has a value receiver, it will accept both pointer and value receivers.
@@ -202,12 +203,12 @@ func RunMethods()  {
 		length: 10,
 		width:  5,
 	}
-	area(rec)
-	rec.area()
+	area(w, rec)
+	rec.area(w)
 	recPointer := &rec
-	recPointer.area() // this line, for convenience will be interpreted by Go as (*recPointer).area() since area has a value receiver.
+	recPointer.area(w) // this line, for convenience will be interpreted by Go as (*recPointer).area() since area has a value receiver.
 
-	fmt.Printf("\nBeginning of pointer receivers in methods vs pointer arguments in functions...\n")
+	fmt.Fprintf(w, "\nBeginning of pointer receivers in methods vs pointer arguments in functions...\n")
 	/*
 	Similar to value arguments, functions with pointer arguments will accept only pointers whereas methods with pointer
 	receivers will accept both pointer and value receiver.
@@ -217,11 +218,11 @@ func RunMethods()  {
 		width:  5,
 	}
 	recPointer2 := &rec2
-	perimeter(recPointer2)
-	recPointer2.perimeter()
-	rec2.perimeter() // this line will be interpreted by the language as (&recPointer2).perimeter() for convenience.
+	perimeter(w, recPointer2)
+	recPointer2.perimeter(w)
+	rec2.perimeter(w) // this line will be interpreted by the language as (&recPointer2).perimeter() for convenience.
 
-	fmt.Printf("\nBeginning of methods with non-struct receivers...\n")
+	fmt.Fprintf(w, "\nBeginning of methods with non-struct receivers...\n")
 	/*
 	So far we have defined methods only on struct types. It is also possible to define methods on non-struct types, but
 	there is a catch. To define a method on a type, the definition of the receiver type and the definition of the method
@@ -235,5 +236,5 @@ func RunMethods()  {
 	num1 := myInt(5)
 	num2 := myInt(10)
 	sum := num1.add(num2)
-	fmt.Println("Sum is", sum)
+	fmt.Fprintln(w, "Sum is", sum)
 }
\ No newline at end of file
@@ -0,0 +1,7 @@
+package methods
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("methods", "methods", RunMethods)
+}
@@ -2,11 +2,12 @@ package oop
 
 import(
 	"fmt"
+	"io"
 	"golang-tutorial/oop/employee"
 )
 
-func RunClasses() {
-	fmt.Printf("\nBeginning of is Go object oriented?\n")
+func RunClasses(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of is Go object oriented?\n")
 	/*
 		Yes and no. Although Go has types and methods and allows an object-oriented style of programming, there is no type
 		hierarchy. The concept of “interface” in Go provides a different approach that we believe is easy to use and in
@@ -15,7 +16,7 @@ func RunClasses() {
 		any sort of data, even built-in types such as plain, “unboxed” integers. They are not restricted to structs (classes).
 	*/
 
-	fmt.Printf("\nBeginning of structs instead of classes...\n")
+	fmt.Fprintf(w, "\nBeginning of structs instead of classes...\n")
 	/*
 	Go does not provide classes but it does provide structs. Methods can be added on structs. This provides the behaviour
 	of bundling the data and methods that operate on the data together akin to a class.
@@ -26,11 +27,11 @@ func RunClasses() {
 		TotalLeaves: 30,
 		LeavesTaken: 20,
 	}
-	e.LeavesRemaining()
+	e.LeavesRemaining(w)
 
-	fmt.Printf("\nBeginning of New() function instead of constructors...\n")
+	fmt.Fprintf(w, "\nBeginning of New() function instead of constructors...\n")
 	var e1 employee.Employee
-	e1.LeavesRemaining()
+	e1.LeavesRemaining(w)
 	/*
 	As you can see, the variable created with the zero value of Employee is unusable. It doesn't have a valid first name,
 	last name and also doesn't have valid leave details.
@@ -44,7 +45,7 @@ func RunClasses() {
 	convention in Go to name this function just New(parameters) instead of NewT(parameters).
 	 */
 	e2 := employee.NewEmployee2("Sam", "Adolf", 30, 20)
-	e2.LeavesRemaining()
+	e2.LeavesRemaining(w)
 	/*
 	Thus you can understand that although Go doesn't support classes, structs can effectively be used instead of classes
 	and methods of signature New(parameters) can be used in the place of constructors.
@@ -1,6 +1,9 @@
 package employee
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 type Employee struct {
 	FirstName string
@@ -9,6 +12,6 @@ type Employee struct {
 	LeavesTaken int
 }
 
-func (e Employee) LeavesRemaining() {
-	fmt.Printf("%s %s has %d leaves remaining", e.FirstName, e.LastName, (e.TotalLeaves - e.LeavesTaken))
+func (e Employee) LeavesRemaining(w io.Writer) {
+	fmt.Fprintf(w, "%s %s has %d leaves remaining", e.FirstName, e.LastName, (e.TotalLeaves - e.LeavesTaken))
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package employee
 
 import (
 	"fmt"
+	"io"
 )
 
 type employee2 struct {
@@ -16,6 +17,6 @@ func NewEmployee2(firstName string, lastName string, totalLeave int, leavesTaken
 	return e
 }
 
-func (e employee2) LeavesRemaining() {
-	fmt.Printf("%s %s has %d leaves remaining", e.firstName, e.lastName, (e.totalLeaves - e.leavesTaken))
+func (e employee2) LeavesRemaining(w io.Writer) {
+	fmt.Fprintf(w, "%s %s has %d leaves remaining", e.firstName, e.lastName, (e.totalLeaves - e.leavesTaken))
 }
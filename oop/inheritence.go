@@ -1,9 +1,12 @@
 package oop
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-func RunInheritance() {
-	fmt.Printf("\nBeginning of introduction...\n")
+func RunInheritance(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction...\n")
 	/*
 	Go does not support inheritance, however it does support composition. The generic definition of composition is "put
 	together". One example of composition is a car. A car is composed of wheels, engine and various other parts.
@@ -12,12 +15,12 @@ func RunInheritance() {
 	can be perfectly represented using composition.
 	*/
 
-	fmt.Printf("\nBeginning of composition example...\n")
+	fmt.Fprintf(w, "\nBeginning of composition example...\n")
 	author1 := author{"Naveen", "Ramanathan", "Golang Enthusiast"}
 	post1 := post{"Inheritence in Go", "aslkdjalskdjsaldkj", author1}
-	post1.details()
+	post1.details(w)
 
-	fmt.Printf("\nBeginning of embedding slice of structures...\n")
+	fmt.Fprintf(w, "\nBeginning of embedding slice of structures...\n")
 	/*
 	We can take this example one step further and create a website using a slice of blog posts :)
 	In website.go, it is not possible to anonymously embed a slice. A field name is required.
@@ -32,6 +35,6 @@ func RunInheritance() {
 		"Go is a concurrent language and not a parallel one",
 		author1,
 	}
-	w := website{[]post{post1, post2, post3}}
-	w.contents()
+	ws := website{[]post{post1, post2, post3}}
+	ws.contents(w)
 }
\ No newline at end of file
@@ -1,15 +1,18 @@
 package oop
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-func RunPolymorphism() {
-	fmt.Printf("\nBeginning of introduction...\n")
+func RunPolymorphism(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction...\n")
 	/*
 	Polymorphism in Go is achieved with the help of interfaces. As we have already discussed, interfaces can be implicitly
 	implemented in Go. A type implements an interface if it provides definitions for all the methods declared in the interface.
 	 */
 
-	fmt.Printf("\nBeginning of Polymorphism using interfaces...\n")
+	fmt.Fprintf(w, "\nBeginning of Polymorphism using interfaces...\n")
 	/*
 	Any type which defines all the methods of an interface is said to implicitly implement that interface.
 	A variable of type interface can hold any value which implements the interface. This property of interfaces is used
@@ -26,7 +29,7 @@ func RunPolymorphism() {
 	bannerAd := advertisement{adName: "Banner Ad", cpc: 2, noOfClicks: 500}
 	popupAd := advertisement{adName: "Popup Ad", cpc: 5, noOfClicks: 750}
 	incomeStreams := []income{project1, project2, project3, bannerAd, popupAd}
-	calculateNetIncome(incomeStreams)
+	calculateNetIncome(w, incomeStreams)
 	/*
 	You would have noticed that we did not make any changes to the calculateNetIncome function though we added a new
 	income stream. It just worked because of polymorphism. Since the new Advertisement type also implemented the Income
@@ -1,6 +1,9 @@
 package oop
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 type post struct {
 	title string
@@ -8,14 +11,14 @@ type post struct {
 	author // this field can also be declared as anonymous field
 }
 
-func (p post) details() {
-	fmt.Println("Title: ", p.title)
-	fmt.Println("Content: ", p.content)
-	fmt.Println("Whenever one struct field is embedded in another, Go gives us the option to access the embedded " +
+func (p post) details(w io.Writer) {
+	fmt.Fprintln(w, "Title: ", p.title)
+	fmt.Fprintln(w, "Content: ", p.content)
+	fmt.Fprintln(w, "Whenever one struct field is embedded in another, Go gives us the option to access the embedded " +
 		"fields as if they were part of the outer struct. This means that p.author.fullName() in below line can be " +
 		"replaced with p.fullName(). Hence the details() method can be rewritten as below,")
-	fmt.Println("Author: ", p.author.fullName())
-	fmt.Println("Like this")
-	fmt.Println("Author: ", p.fullName())
-	fmt.Println("Bio: ", p.author.bio)
+	fmt.Fprintln(w, "Author: ", p.author.fullName())
+	fmt.Fprintln(w, "Like this")
+	fmt.Fprintln(w, "Author: ", p.fullName())
+	fmt.Fprintln(w, "Bio: ", p.author.bio)
 }
\ No newline at end of file
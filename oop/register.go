@@ -0,0 +1,9 @@
+package oop
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("oop", "classes", RunClasses)
+	lessons.Register("oop", "inheritance", RunInheritance)
+	lessons.Register("oop", "polymorphism", RunPolymorphism)
+}
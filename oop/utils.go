@@ -1,12 +1,15 @@
 package oop
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-func calculateNetIncome(ic []income) {
+func calculateNetIncome(w io.Writer, ic []income) {
 	var netincome int = 0
 	for _, income := range ic {
-		fmt.Printf("Income From %s = $%d\n", income.source(), income.calculate())
+		fmt.Fprintf(w, "Income From %s = $%d\n", income.source(), income.calculate())
 		netincome += income.calculate()
 	}
-	fmt.Printf("Net income of organisation = $%d", netincome)
+	fmt.Fprintf(w, "Net income of organisation = $%d", netincome)
 }
\ No newline at end of file
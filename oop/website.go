@@ -1,14 +1,17 @@
 package oop
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 type website struct {
 	posts []post // It is not possible to anonymously embed a slice([]posts). A field name is required.
 }
 
-func (w website) contents() {
-	for _, v := range w.posts {
-		v.details()
-		fmt.Println()
+func (ws website) contents(w io.Writer) {
+	for _, v := range ws.posts {
+		v.details(w)
+		fmt.Fprintln(w)
 	}
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package packages
 
 import (
 	"fmt"
+	"io"
 	"golang-tutorial/packages/rectangle"
 	"log"
 )
@@ -25,15 +26,15 @@ func init() {
 	}
 }
 
-func RunPackages() {
-	fmt.Printf("\nBeginning of what are packages and why are they used?\n")
+func RunPackages(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of what are packages and why are they used?\n")
 	/*
 	So far we have seen go programs which have only one file which has a main function with a couple of other functions.
 	In real world scenarios this approach to writing all source code in a single file will not work. It becomes
 	impossible to reuse and maintain code written this way. This is where packages save the day.
 	 */
 
-	fmt.Printf("\nBeginning of main function and main package\n")
+	fmt.Fprintf(w, "\nBeginning of main function and main package\n")
 	/*
 	Every executable go application must contain a main function. This function is the entry point for execution. The
 	main function should reside in the main package.
@@ -41,14 +42,14 @@ func RunPackages() {
 	be first line of every go source file.
 	*/
 
-	fmt.Printf("\nBeginning of what happens when running go install geometry\n")
+	fmt.Fprintf(w, "\nBeginning of what happens when running go install geometry\n")
 	/*
 	This command searches for a file with a main function inside the geometry folder. In this case it finds geometry.go.
 	It then compiles it and generates a binary named geometry(geometry.exe in the case of windows) inside the bin folder
 	of the workspace.
 	 */
 
-	fmt.Printf("\nBeginning of exported names\n")
+	fmt.Fprintf(w, "\nBeginning of exported names\n")
 	/*
 	We capitalised the functions Area and Diagonal in the rectangle package. This has a special meaning in Go. Any
 	variable or function which starts with a capital letter are exported names in go. Only exported functions and
@@ -57,11 +58,11 @@ func RunPackages() {
 	Hence if you want to access a function outside of a package, it should be capitalised.
 	 */
 	var rectLen, rectWidth float64 = 6, 7
-	fmt.Println("Geometrical shape properties")
-	fmt.Printf("area of rectangle %.2f\n", rectangle.Area(rectLen, rectWidth))
-	fmt.Printf("diagonal of the rectangle %.2f\n ",rectangle.Diagonal(rectLen, rectWidth))
+	fmt.Fprintln(w, "Geometrical shape properties")
+	fmt.Fprintf(w, "area of rectangle %.2f\n", rectangle.Area(rectLen, rectWidth))
+	fmt.Fprintf(w, "diagonal of the rectangle %.2f\n ",rectangle.Diagonal(rectLen, rectWidth))
 
-	fmt.Printf("\nBeginning of init functions\n")
+	fmt.Fprintf(w, "\nBeginning of init functions\n")
 	/*
 	Every package can contain a init function. The init function should not have any return type and should not have any
 	parameters. The init function cannot be called explicitly in our source code. The init function looks like below:
@@ -76,9 +77,9 @@ func RunPackages() {
 	If a package imports other packages, the imported packages are initialised first.
 	A package will be initialised only once even if it is imported from multiple packages.
 	*/
-	fmt.Println("Geometrical shape properties")
-	fmt.Printf("area of rectangle %.2f\n", rectangle.Area(rectLen, rectWidth))
-	fmt.Printf("diagonal of the rectangle %.2f ",rectangle.Diagonal(rectLen, rectWidth))
+	fmt.Fprintln(w, "Geometrical shape properties")
+	fmt.Fprintf(w, "area of rectangle %.2f\n", rectangle.Area(rectLen, rectWidth))
+	fmt.Fprintf(w, "diagonal of the rectangle %.2f ",rectangle.Diagonal(rectLen, rectWidth))
 	/*
 	In the below example; The order of initialisation of the main package is:
 		- The imported packages are first initialised. Hence rectangle package is initialised first.
@@ -87,7 +88,7 @@ func RunPackages() {
 		- RunPackages() function is called at last
 	*/
 
-	fmt.Printf("\nBeginning of use of blank identifier\n")
+	fmt.Fprintf(w, "\nBeginning of use of blank identifier\n")
 	/*
 	It is illegal in Go to import a package and not to use it anywhere in the code. The compiler will complain if you do
 	so. The reason for this is to avoid bloating of unused packages which will significantly increase the compilation
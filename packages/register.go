@@ -0,0 +1,7 @@
+package packages
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("packages", "packages", RunPackages)
+}
@@ -0,0 +1,7 @@
+package pointers
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("pointers", "pointers", RunPointers)
+}
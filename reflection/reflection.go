@@ -2,6 +2,7 @@ package reflection
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 )
 
@@ -18,36 +19,36 @@ type employee struct {
 	country string
 }
 
-func createQuery(q interface{}) {
+func createQuery(w io.Writer, q interface{}) {
 	t := reflect.TypeOf(q)
 	v := reflect.ValueOf(q)
-	fmt.Println("Type ", t)
-	fmt.Println("Value ", v)
+	fmt.Fprintln(w, "Type ", t)
+	fmt.Fprintln(w, "Value ", v)
 }
 
-func createQueryKind(q interface{}) {
+func createQueryKind(w io.Writer, q interface{}) {
 	typeOf := reflect.TypeOf(q)
 	valueOf := reflect.ValueOf(q)
 	tKind := typeOf.Kind()
 	vKind := valueOf.Kind()
-	fmt.Println("Type ", typeOf)
-	fmt.Println("Value ", valueOf)
-	fmt.Println("typeOf kind ", tKind)
-	fmt.Println("valueOf kind ", vKind)
+	fmt.Fprintln(w, "Type ", typeOf)
+	fmt.Fprintln(w, "Value ", valueOf)
+	fmt.Fprintln(w, "typeOf kind ", tKind)
+	fmt.Fprintln(w, "valueOf kind ", vKind)
 }
 
-func createQueryNum(q interface{}) {
+func createQueryNum(w io.Writer, q interface{}) {
 	//  We first check whether the Kind of q is a struct because the NumField method works only on struct.
 	if reflect.ValueOf(q).Kind() == reflect.Struct {
 		v := reflect.ValueOf(q)
-		fmt.Println("Number of fields", v.NumField())
+		fmt.Fprintln(w, "Number of fields", v.NumField())
 		for i := 0; i < v.NumField(); i++ {
-			fmt.Printf("Field:%d type:%T value:%v\n", i, v.Field(i), v.Field(i))
+			fmt.Fprintf(w, "Field:%d type:%T value:%v\n", i, v.Field(i), v.Field(i))
 		}
 	}
 }
 
-func createQueryComplete(q interface{}) {
+func createQueryComplete(w io.Writer, q interface{}) {
 	if reflect.ValueOf(q).Kind() == reflect.Struct {
 		t := reflect.TypeOf(q).Name()
 		query := fmt.Sprintf("insert into %s(ordId) values(", t)
@@ -57,9 +58,9 @@ func createQueryComplete(q interface{}) {
 			query = fmt.Sprintf("insert into %s(empName, empId, empTown, empSalary, empCountry) values(", t)
 		}
 		v := reflect.ValueOf(q)
-		fmt.Println("valueOf = ", reflect.ValueOf(q))
-		fmt.Println("typeOf = ", reflect.TypeOf(q))
-		fmt.Println("numField = ", reflect.ValueOf(q).NumField())
+		fmt.Fprintln(w, "valueOf = ", reflect.ValueOf(q))
+		fmt.Fprintln(w, "typeOf = ", reflect.TypeOf(q))
+		fmt.Fprintln(w, "numField = ", reflect.ValueOf(q).NumField())
 		for i := 0; i < v.NumField(); i++ {
 			switch v.Field(i).Kind() {
 			case reflect.Int:
@@ -75,19 +76,19 @@ func createQueryComplete(q interface{}) {
 					query = fmt.Sprintf("%s, \"%s\"", query, v.Field(i).String())
 				}
 			default:
-				fmt.Println("Unsupported type")
+				fmt.Fprintln(w, "Unsupported type")
 				return
 			}
 		}
 		query = fmt.Sprintf("%s)", query)
-		fmt.Println(query)
+		fmt.Fprintln(w, query)
 		return
 	}
-	fmt.Println("unsupported type")
+	fmt.Fprintln(w, "unsupported type")
 }
 
-func RunReflection() {
-	fmt.Printf("\nBeginning of introduction to reflection...\n")
+func RunReflection(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction to reflection...\n")
 	/*
 	Reflection is the ability of a program to inspect its variables and values at run time and find their type.
 	The first question anyone gets when learning about reflection is why do we even need to inspect a variable and
@@ -96,10 +97,10 @@ func RunReflection() {
 	answer.
 	*/
 	i := 10
-	fmt.Printf("%d %T", i, i)
+	fmt.Fprintf(w, "%d %T", i, i)
 	// In the above program the type of i is known at compile time but we need to understand it at the runtime.
 
-	fmt.Printf("\nBeginning of why reflection...\n")
+	fmt.Fprintf(w, "\nBeginning of why reflection...\n")
 	/*
 	Assume that you have a struct type order, and another type employee. You want a single function createQuery() depending
 	on the different struct types and generating different queries. As final, your createQuery() function should work with
@@ -107,7 +108,7 @@ func RunReflection() {
 	find its fields and then create the query. This is where reflection is useful.
 	 */
 
-	fmt.Printf("\nBeginning of reflect package...\n")
+	fmt.Fprintf(w, "\nBeginning of reflect package...\n")
 	/*
 	The reflect package implements run-time reflection in Go. The reflect package helps to identify the underlying
 	concrete type and the value of a interface{} variable. This is exactly what we need. The createQuery function
@@ -124,7 +125,7 @@ func RunReflection() {
 		ordId:      456,
 		customerId: 56,
 	}
-	createQuery(o)
+	createQuery(w, o)
 	/*
 	2- reflect.Kind:
 	There is one more important type in the re	reflection package called Kind. The types Kind and Type in the reflection
@@ -134,7 +135,7 @@ func RunReflection() {
 		ordId:      456,
 		customerId: 56,
 	}
-	createQueryKind(o)
+	createQueryKind(w, o)
 	/*
 	3- NumField() and Field() methods:
 	The NumField() method returns the number of fields in a struct and the Field(i int) method returns the reflect.Value
@@ -144,24 +145,24 @@ func RunReflection() {
 		ordId:      456,
 		customerId: 56,
 	}
-	createQueryNum(o)
+	createQueryNum(w, o)
 	/*
 	4- Int() and String() methods:
 	The methods Int and String help extract the reflect.Value as an int64 and string respectively.
 	 */
 	a := 56
 	x := reflect.ValueOf(a).Int()
-	fmt.Printf("type:%T value:%v\n", x, x)
+	fmt.Fprintf(w, "type:%T value:%v\n", x, x)
 	b := "Naveen"
 	y := reflect.ValueOf(b).String()
-	fmt.Printf("type:%T value:%v\n", y, y)
+	fmt.Fprintf(w, "type:%T value:%v\n", y, y)
 
-	fmt.Printf("\nBeginning of complete program with reflection...\n")
+	fmt.Fprintf(w, "\nBeginning of complete program with reflection...\n")
 	f := order{
 		ordId:      456,
 		customerId: 56,
 	}
-	createQueryComplete(f)
+	createQueryComplete(w, f)
 	e := employee{
 		name:    "Naveen",
 		id:      565,
@@ -169,11 +170,11 @@ func RunReflection() {
 		salary:  90000,
 		country: "India",
 	}
-	createQueryComplete(e)
+	createQueryComplete(w, e)
 	i = 90
-	createQueryComplete(i)
+	createQueryComplete(w, i)
 
-	fmt.Printf("\nBeginning of conclusion...\n")
+	fmt.Fprintf(w, "\nBeginning of conclusion...\n")
 	/*
 	Should reflection be used?
 	Rob Pike says "Clear is better than clever. Reflection is never clear."
@@ -0,0 +1,7 @@
+package reflection
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("reflection", "reflection", RunReflection)
+}
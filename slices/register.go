@@ -0,0 +1,7 @@
+package slices
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("slices", "slices", RunSlices)
+}
@@ -0,0 +1,7 @@
+package strings
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("strings", "strings", RunStrings)
+}
@@ -2,38 +2,39 @@ package strings
 
 import (
 	"fmt"
+	"io"
 	"unicode/utf8"
 )
 
-func printBytes(s string) {
+func printBytes(w io.Writer, s string) {
 	for i := 0; i < len(s); i++ {
-		fmt.Printf("%x ", s[i])
+		fmt.Fprintf(w, "%x ", s[i])
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
 }
 
-func printChars(s string) {
+func printChars(w io.Writer, s string) {
 	for i:= 0; i < len(s); i++ {
-		fmt.Printf("%c ",s[i])
+		fmt.Fprintf(w, "%c ",s[i])
 	}
 }
 
-func printCharsWithRune(s string) {
+func printCharsWithRune(w io.Writer, s string) {
 	runes := []rune(s)
 	for i := 0; i < len(runes); i++ {
-		fmt.Printf("%c ", runes[i])
+		fmt.Fprintf(w, "%c ", runes[i])
 	}
 }
 
-func printCharsAndBytes(s string) {
-	fmt.Println("string", s, "has length", len(s), "bytes")
+func printCharsAndBytes(w io.Writer, s string) {
+	fmt.Fprintln(w, "string", s, "has length", len(s), "bytes")
 	for index, rune := range s {
-		fmt.Printf("%c starts at byte %d\n", rune, index)
+		fmt.Fprintf(w, "%c starts at byte %d\n", rune, index)
 	}
 }
 
-func getStringLength(s string) {
-	fmt.Printf("length of %s is %d\n", s, utf8.RuneCountInString(s))
+func getStringLength(w io.Writer, s string) {
+	fmt.Fprintf(w, "length of %s is %d\n", s, utf8.RuneCountInString(s))
 }
 
 func mutate(s []rune) string {
@@ -41,19 +42,19 @@ func mutate(s []rune) string {
 	return string(s)
 }
 
-func RunStrings() {
-	fmt.Printf("\nBeginning of introduction to strings...\n")
+func RunStrings(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of introduction to strings...\n")
 	/*
 	A string in Go is a slice of bytes.
 	Strings in Go are Unicode compliant and are UTF-8 Encoded.
 	 */
 
-	fmt.Printf("\nBeginning of accessing individual bytes of a string...\n")
+	fmt.Fprintf(w, "\nBeginning of accessing individual bytes of a string...\n")
 	/*
 	Since a string is a slice of bytes, it's possible to access each byte of a string.
 	 */
 	name := "Hello World"
-	printBytes(name)
+	printBytes(w, name)
 	/*
 	On above program, len(s) on function printBytes() returns the number of bytes in the string and we use a for loop to
 	print those bytes in hexadecimal notation. %x is the format specifier for hexadecimal.
@@ -61,9 +62,9 @@ func RunStrings() {
 	understand strings better.
 	*/
 	name = "Señor"
-	printBytes(name)
-	fmt.Printf("\n")
-	printChars(name)
+	printBytes(w, name)
+	fmt.Fprintf(w, "\n")
+	printChars(w, name)
 	/*
 	If we try to print the characters of Señor, it outputs S e Ã ± o r which is wrong. Why does this program break for
 	Señor when it's perfectly alright with Hello World. The reason is that the Unicode code point of ñ is U+00F1 and its
@@ -72,68 +73,68 @@ func RunStrings() {
 	This is where rune saves us.
 	 */
 
-	fmt.Printf("\nBeginning of rune...\n")
+	fmt.Fprintf(w, "\nBeginning of rune...\n")
 	/*
 	A rune is a builtin type in Go and it's the alias of int32. rune represents a Unicode code point in Go. It does not
 	matter how many bytes the code point occupies, it can be represented by a rune.
 	 */
 	name = "Señor"
-	printBytes(name)
-	fmt.Printf("\n")
-	printCharsWithRune(name)
-	fmt.Printf("\n")
+	printBytes(w, name)
+	fmt.Fprintf(w, "\n")
+	printCharsWithRune(w, name)
+	fmt.Fprintf(w, "\n")
 	/*
 	In the printCharsWithRune function, the string is converted to a slice of runes.
 	 */
 
-	fmt.Printf("\nBeginning of for range loop on a string...\n")
+	fmt.Fprintf(w, "\nBeginning of for range loop on a string...\n")
 	name = "Señor"
-	printCharsAndBytes(name)
+	printCharsAndBytes(w, name)
 	/*
 	In the above program, the string is iterated using for range loop. The loop returns the position of the byte where
 	the rune starts along with the rune.
 	From the above output it's clear that ñ occupies 2 bytes :).
 	*/
 
-	fmt.Printf("\nBeginning of constructing string from slice of bytes...\n")
+	fmt.Fprintf(w, "\nBeginning of constructing string from slice of bytes...\n")
 	byteSlice := []byte{0x43, 0x61, 0x66, 0xC3, 0xA9}
 	str := string(byteSlice)
-	fmt.Println(str)
+	fmt.Fprintln(w, str)
 	/*
 	byteSlice in the program above contains the UTF-8 Encoded hex bytes of the string "Café". The program outputs Café.
 	What if we have the decimal equivalent of hex values. Will the above program work? Lets check it out.
 	 */
 	byteSlice = []byte{67, 97, 102, 195, 169} // decimal equivalent of {'\x43', '\x61', '\x66', '\xC3', '\xA9'}
 	str = string(byteSlice)
-	fmt.Println(str)
+	fmt.Fprintln(w, str)
 
-	fmt.Printf("\nBeginning of constructing a string from slice of runes...\n")
+	fmt.Fprintf(w, "\nBeginning of constructing a string from slice of runes...\n")
 	runeSlice := []rune{0x0053, 0x0065, 0x00f1, 0x006f, 0x0072}
 	str = string(runeSlice)
-	fmt.Println(str)
+	fmt.Fprintln(w, str)
 	/*
 	In the above program runeSlice contains the Unicode code points of the string Señor in hexadecimal. The program
 	outputs Señor.
 	 */
 
-	fmt.Printf("\nBeginning of length of the string...\n")
+	fmt.Fprintf(w, "\nBeginning of length of the string...\n")
 	/*
 	The func RuneCountInString(s string) (n int) function of the utf8 package is used to find the length of the string.
 	This method takes a string as argument and returns the number of runes in it.
 	*/
 	word1 := "Señor"
-	getStringLength(word1)
+	getStringLength(w, word1)
 	word2 := "Pets"
-	getStringLength(word2)
+	getStringLength(w, word2)
 
-	fmt.Printf("\nBeginning of strings are immutable...\n")
+	fmt.Fprintf(w, "\nBeginning of strings are immutable...\n")
 	/*
 	Strings are immutable in Go. Once a string is created it's not possible to change it.
 	To workaround this string immutability, strings are converted to a slice of runes. Then that slice is mutated
 	with whatever changes needed and converted back to a new string.
 	*/
 	h := "hello"
-	fmt.Println(mutate([]rune(h)))
+	fmt.Fprintln(w, mutate([]rune(h)))
 	/*
 	In the above program, the mutate function accepts a rune slice as argument. It then changes the first element of the
 	slice to 'a', converts the rune back to string and returns it. h is converted to a slice of runes and passed to
@@ -0,0 +1,7 @@
+package structures
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("structures", "structures", RunStructures)
+}
@@ -0,0 +1,7 @@
+package switch_statement
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("switch_statement", "switch_statement", RunSwitchStatement)
+}
@@ -0,0 +1,7 @@
+package types
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("types", "types", RunTypes)
+}
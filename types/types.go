@@ -2,11 +2,12 @@ package types
 
 import (
 	"fmt"
+	"io"
 	"unsafe"
 )
 
-func RunTypes() {
-	fmt.Printf("\nBeginning of data types in Go...\n")
+func RunTypes(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of data types in Go...\n")
 	/*
 	The following are the basic types available in go:
 	- bool
@@ -20,16 +21,16 @@ func RunTypes() {
 	- string
 	 */
 
-	fmt.Printf("\nBeginning of bool...\n")
+	fmt.Fprintf(w, "\nBeginning of bool...\n")
 	a := true
 	b := false
-	fmt.Println("a:", a, "b:", b)
+	fmt.Fprintln(w, "a:", a, "b:", b)
 	c := a && b
-	fmt.Println("c:", c)
+	fmt.Fprintln(w, "c:", c)
 	d := a || b
-	fmt.Println("d:", d)
+	fmt.Fprintln(w, "d:", d)
 
-	fmt.Printf("\nBeginning of signed integers...\n")
+	fmt.Fprintf(w, "\nBeginning of signed integers...\n")
 	/*
 	int8: represents 8 bit signed integers
 	size: 8 bits
@@ -54,7 +55,7 @@ func RunTypes() {
 	 */
 	var e int = 89
 	f := 95
-	fmt.Println("value of a is", e, "and b is", f)
+	fmt.Fprintln(w, "value of a is", e, "and b is", f)
 	/*
 	The type of a variable can be printed using %T format specifier in Printf function. Go has a package unsafe which
 	has a Sizeof function which returns in bytes the size of the variable passed to it. unsafe package should be used
@@ -62,11 +63,11 @@ func RunTypes() {
 	 */
 	var ab int = 89
 	ba := 95
-	fmt.Println("value of a is", ab, "and b is", ba)
-	fmt.Printf("type of a is %T, size of a is %d", ab, unsafe.Sizeof(ab)) //type and size of a
-	fmt.Printf("\ntype of b is %T, size of b is %d", ba, unsafe.Sizeof(ba)) //type and size of b
+	fmt.Fprintln(w, "value of a is", ab, "and b is", ba)
+	fmt.Fprintf(w, "type of a is %T, size of a is %d", ab, unsafe.Sizeof(ab)) //type and size of a
+	fmt.Fprintf(w, "\ntype of b is %T, size of b is %d", ba, unsafe.Sizeof(ba)) //type and size of b
 
-	fmt.Printf("\nBeginning of unsigned integers...\n")
+	fmt.Fprintf(w, "\nBeginning of unsigned integers...\n")
 	/*
 	uint8: represents 8 bit unsigned integers
 	size: 8 bits
@@ -89,7 +90,7 @@ func RunTypes() {
 	range : 0 to 4294967295 in 32 bit systems and 0 to 18446744073709551615 in 64 bit systems
 	 */
 
-	fmt.Printf("\nBeginning of floating point types...\n")
+	fmt.Fprintf(w, "\nBeginning of floating point types...\n")
 	/*
 	float32: 32 bit floating point numbers
 	float64: 64 bit floating point numbers(if you define a float variable without specifying a type, it will be float64)
@@ -97,14 +98,14 @@ func RunTypes() {
 	// The type of a and b is inferred from the value assigned to them. In this case a and b are of type float64.(float64
 	//is the default type for floating point values)
 	ca, da := 5.67, 8.97
-	fmt.Printf("type of a %T b %T\n", ca, da)
+	fmt.Fprintf(w, "type of a %T b %T\n", ca, da)
 	sum := ca + da
 	diff := ca - da
-	fmt.Println("sum", sum, "diff", diff)
+	fmt.Fprintln(w, "sum", sum, "diff", diff)
 	no1, no2 := 56, 89
-	fmt.Println("sum", no1 + no2, "diff", no1 - no2)
+	fmt.Fprintln(w, "sum", no1 + no2, "diff", no1 - no2)
 
-	fmt.Printf("\nBeginning of complex types...\n")
+	fmt.Fprintf(w, "\nBeginning of complex types...\n")
 	/*
 	complex64: complex numbers which have float32 real and imaginary parts
 	complex128: complex numbers with float64 real and imaginary parts
@@ -120,17 +121,17 @@ func RunTypes() {
 	c1 := complex(5, 7)
 	c2 := 8 + 27i
 	cadd := c1 + c2
-	fmt.Println("sum:", cadd)
+	fmt.Fprintln(w, "sum:", cadd)
 	cmul := c1 * c2
-	fmt.Println("product:", cmul)
+	fmt.Fprintln(w, "product:", cmul)
 
-	fmt.Printf("\nBeginning of other numeric types...\n")
+	fmt.Fprintf(w, "\nBeginning of other numeric types...\n")
 	/*
 	byte is an alias of uint8
 	rune is an alias of int32
 	 */
 
-	fmt.Printf("\nBeginning of string type...\n")
+	fmt.Fprintf(w, "\nBeginning of string type...\n")
 	/*
 	Strings are a collection of bytes in golang. It's alright if this definition doesn't make any sense. For now we can
 	assume a string to be a collection of characters.
@@ -138,21 +139,21 @@ func RunTypes() {
 	first := "Naveen"
 	last := "Ramanathan"
 	name := first +" "+ last
-	fmt.Println("My name is",name)
+	fmt.Fprintln(w, "My name is",name)
 
-	fmt.Printf("\nBeginning of type conversion...\n")
+	fmt.Fprintf(w, "\nBeginning of type conversion...\n")
 	/*
 	Go is very strict about explicit typing. There is no automatic type promotion or conversion.
 	 */
 	i := 55      //int
 	j := 67.8    //float64
 	sum2 := i + int(j) //int + float64 not allowed, so we must convert one of them to other
-	fmt.Println(sum2)
+	fmt.Fprintln(w, sum2)
 	/*
 	The same is the case with assignment. Explicit type conversion is required to assign a variable of one type to
 	another.
 	 */
 	ij := 10
 	var ji float64 = float64(ij) //this statement will not work without explicit conversion
-	fmt.Println("j", ji)
+	fmt.Fprintln(w, "j", ji)
 }
\ No newline at end of file
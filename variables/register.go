@@ -0,0 +1,7 @@
+package variables
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("variables", "variables", RunVariables)
+}
@@ -0,0 +1,7 @@
+package variadic_functions
+
+import "golang-tutorial/lessons"
+
+func init() {
+	lessons.Register("variadic_functions", "variadic_functions", RunVariadicFunctions)
+}
@@ -1,49 +1,52 @@
 package variadic_functions
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-func findWithVariadicArguments(num int, nums ...int) {
-	fmt.Printf("type of nums is %T\n", nums)
+func findWithVariadicArguments(w io.Writer, num int, nums ...int) {
+	fmt.Fprintf(w, "type of nums is %T\n", nums)
 	found := false
 	for i, v := range nums {
 		if v == num {
-			fmt.Println(num, "found at index", i, "in", nums)
+			fmt.Fprintln(w, num, "found at index", i, "in", nums)
 			found = true
 		}
 	}
 	if !found {
-		fmt.Println(num, "not found in ", nums)
+		fmt.Fprintln(w, num, "not found in ", nums)
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
 }
 
-func findWithSliceArguments(num int, nums []int) {
-	fmt.Printf("type of nums is %T\n", nums)
+func findWithSliceArguments(w io.Writer, num int, nums []int) {
+	fmt.Fprintf(w, "type of nums is %T\n", nums)
 	found := false
 	for i, v := range nums {
 		if v == num {
-			fmt.Println(num, "found at index", i, "in", nums)
+			fmt.Fprintln(w, num, "found at index", i, "in", nums)
 			found = true
 		}
 	}
 	if !found {
-		fmt.Println(num, "not found in ", nums)
+		fmt.Fprintln(w, num, "not found in ", nums)
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
 }
 
 func change(s ...string) {
 	s[0] = "Go"
 }
 
-func changeAndAdd(s ...string) {
+func changeAndAdd(w io.Writer, s ...string) {
 	s[0] = "Go"
 	s = append(s, "playground")
-	fmt.Println(s)
+	fmt.Fprintln(w, s)
 }
 
-func RunVariadicFunctions() {
-	fmt.Printf("\nBeginning of description of variadic functions...\n")
+func RunVariadicFunctions(w io.Writer) {
+	fmt.Fprintf(w, "\nBeginning of description of variadic functions...\n")
 	/*
 	Functions in general accept only a fixed number of arguments. A variadic function is a function that accepts a variable
 	number of arguments. If the last parameter of a function definition is prefixed by ellipsis ..., then the function
@@ -62,11 +65,11 @@ func RunVariadicFunctions() {
 	use ... with non-final parameter b
 	*/
 
-	fmt.Printf("\nBeginning of examples...\n")
-	findWithVariadicArguments(89, 89, 90, 95)
-	findWithVariadicArguments(45, 56, 67, 45, 90, 109)
-	findWithVariadicArguments(78, 38, 56, 98)
-	findWithVariadicArguments(87)
+	fmt.Fprintf(w, "\nBeginning of examples...\n")
+	findWithVariadicArguments(w, 89, 89, 90, 95)
+	findWithVariadicArguments(w, 45, 56, 67, 45, 90, 109)
+	findWithVariadicArguments(w, 78, 38, 56, 98)
+	findWithVariadicArguments(w, 87)
 	/*
 	The way variadic functions work is by converting the variable number of arguments to a slice of the type of the
 	variadic parameter. For instance, 78 of the program above, the variable number of arguments to the find function
@@ -74,16 +77,16 @@ func RunVariadicFunctions() {
 	the compiler to a slice of type int []int{89, 90, 95} and then it will be passed to the find function.
 	 */
 
-	fmt.Printf("\nBeginning of slice arguments vs variadic arguments...\n")
+	fmt.Fprintf(w, "\nBeginning of slice arguments vs variadic arguments...\n")
 	/*
 	We should definitely have a question lingering in your mind now. In the above section, we learned that the variadic
 	arguments to a function are in fact converted a slice. Then why do we even need variadic functions when we can
 	achieve the same functionality using slices? I have rewritten the program above using slices below.
 	 */
-	findWithSliceArguments(89, []int{89, 90, 95})
-	findWithSliceArguments(45, []int{56, 67, 45, 90, 109})
-	findWithSliceArguments(78, []int{38, 56, 98})
-	findWithSliceArguments(87, []int{})
+	findWithSliceArguments(w, 89, []int{89, 90, 95})
+	findWithSliceArguments(w, 45, []int{56, 67, 45, 90, 109})
+	findWithSliceArguments(w, 78, []int{38, 56, 98})
+	findWithSliceArguments(w, 87, []int{})
 	/*
 	The following of the advantages of using variadic arguments instead of slices:
 		- There is no need to create a slice during each function call. If you look at the program above, we have created
@@ -94,7 +97,7 @@ func RunVariadicFunctions() {
 		- I personally feel that the program with variadic functions is more readable than the once with slices :)
 	*/
 
-	fmt.Printf("\nBeginning of append is a variadic function...\n")
+	fmt.Fprintf(w, "\nBeginning of append is a variadic function...\n")
 	/*
 	Have you ever wondered how the append function in the standard library used to append values to a slice accepts any
 	number of arguments. It's because it's a variadic function.
@@ -103,14 +106,14 @@ func RunVariadicFunctions() {
 	accept a variable number of arguments.
 	*/
 
-	fmt.Printf("\nBeginning of passing a slice to a variadic function...\n")
+	fmt.Fprintf(w, "\nBeginning of passing a slice to a variadic function...\n")
 	/*
 	Below commented lines will not work, because these variadic arguments will be converted to a slice of type int since
 	findWithVariadicArguments expects variadic int arguments. In this case, nums is already a []int slice and the compiler tries to create a
 	new []int i.e the compiler tries to do.
 	*/
 	// nums := []int{89, 90, 95}
-	// findWithVariadicArguments(89, nums)
+	// findWithVariadicArguments(w, 89, nums)
 	/*
 	So is there a way to pass a slice to a variadic function? The answer is yes.
 	There is a syntactic sugar which can be used to pass a slice to a variadic function. You have to suffix the slice
@@ -119,15 +122,15 @@ func RunVariadicFunctions() {
 	the program will compiled.
 	*/
 	nums := []int{89, 90, 95}
-	findWithVariadicArguments(89, nums...)
+	findWithVariadicArguments(w, 89, nums...)
 	/*
 	On above lines of codes, the slice is directly passed to the function without a new slice being created.
 	 */
 
-	fmt.Printf("\nBeginning of gotcha...\n")
+	fmt.Fprintf(w, "\nBeginning of gotcha...\n")
 	welcome := []string{"hello", "world"}
 	change(welcome...)
-	fmt.Println(welcome)
+	fmt.Fprintln(w, welcome)
 	/*
 	What happens on the above lines of codes?
 	We are using the syntactic sugar ... and passing the slice as a variadic argument to the change function.
@@ -135,6 +138,6 @@ func RunVariadicFunctions() {
 	new slice being created. Hence welcome will be passed to the change function as argument.
 	 */
 	welcome2 := []string{"hello", "world"}
-	changeAndAdd(welcome2...)
-	fmt.Println(welcome2)
+	changeAndAdd(w, welcome2...)
+	fmt.Fprintln(w, welcome2)
 }
\ No newline at end of file